@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lenevi/go-magistr-lesson2-zhiltsovEA/podvalidate"
+)
+
+/*************** Baseline ****************/
+// baselineEntry identifies a known error. Lines are left out on purpose, so
+// edits elsewhere in a file do not bring its known errors back.
+type baselineEntry struct {
+	File    string `json:"file"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// baseline counts the known errors; an error is only suppressed as many
+// times as it was recorded.
+type baseline map[baselineEntry]int
+
+func entryOf(path string, e *podvalidate.ValidationError) baselineEntry {
+	return baselineEntry{File: filepath.ToSlash(path), Code: string(e.Code), Message: e.Message}
+}
+
+func loadBaseline(path string) (baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []baselineEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	b := make(baseline)
+	for _, e := range entries {
+		b[e]++
+	}
+	return b, nil
+}
+
+// filter drops the errors of path recorded in b and returns the new ones.
+func (b baseline) filter(path string, errs []*podvalidate.ValidationError) []*podvalidate.ValidationError {
+	var res []*podvalidate.ValidationError
+	for _, e := range errs {
+		if k := entryOf(path, e); b[k] > 0 {
+			b[k]--
+			continue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+func writeBaseline(path string, entries []baselineEntry) error {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.Message < b.Message
+	})
+	if entries == nil {
+		entries = []baselineEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lenevi/go-magistr-lesson2-zhiltsovEA/podvalidate"
+)
+
+/*************** Config ****************/
+const defaultConfigFile = ".yamlvalid.yaml"
+
+// config mirrors .yamlvalid.yaml. Unset fields keep the built-in defaults.
+type config struct {
+	RegistryPrefix     *string  `yaml:"registryPrefix"`
+	AllowedAPIVersions []string `yaml:"allowedApiVersions"`
+	IgnoreCodes        []string `yaml:"ignoreCodes"`
+	AllowedOS          []string `yaml:"allowedOS"`
+	// Severity maps codes to warn or error, like -severity.
+	Severity map[string]string `yaml:"severity"`
+}
+
+// loadConfig reads path, or .yamlvalid.yaml from the current directory when
+// path is empty. A missing default file is not an error.
+func loadConfig(path string) (*config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// apply copies the config into opts, skipping options whose command-line
+// flag was given explicitly.
+func (c *config) apply(opts *podvalidate.Options, set map[string]bool) error {
+	if c.RegistryPrefix != nil && !set["registry"] {
+		opts.Registry = *c.RegistryPrefix
+	}
+	if c.AllowedAPIVersions != nil && !set["api-versions"] {
+		opts.APIVersions = c.AllowedAPIVersions
+	}
+	if c.IgnoreCodes != nil && !set["ignore"] {
+		opts.IgnoreCodes = nil
+		for _, code := range c.IgnoreCodes {
+			opts.IgnoreCodes = append(opts.IgnoreCodes, podvalidate.Code(code))
+		}
+	}
+	if c.AllowedOS != nil && !set["allowed-os"] {
+		opts.AllowedOS = c.AllowedOS
+	}
+	if c.Severity != nil && !set["severity"] {
+		opts.Severities = make(map[podvalidate.Code]podvalidate.Severity)
+		for code, level := range c.Severity {
+			sev, err := parseSeverity(level)
+			if err != nil {
+				return err
+			}
+			opts.Severities[podvalidate.Code(code)] = sev
+		}
+	}
+	return nil
+}
+
+func parseSeverity(level string) (podvalidate.Severity, error) {
+	switch level {
+	case "warn", "warning":
+		return podvalidate.SeverityWarning, nil
+	case "error":
+		return podvalidate.SeverityError, nil
+	}
+	return 0, fmt.Errorf("unknown severity '%s'", level)
+}
+
+// parseSeverities reads CODE:level pairs as given to -severity.
+func parseSeverities(pairs []string) (map[podvalidate.Code]podvalidate.Severity, error) {
+	res := make(map[podvalidate.Code]podvalidate.Severity)
+	for _, p := range pairs {
+		code, level, ok := strings.Cut(p, ":")
+		if !ok {
+			return nil, fmt.Errorf("severity '%s' is not CODE:level", p)
+		}
+		sev, err := parseSeverity(level)
+		if err != nil {
+			return nil, err
+		}
+		res[podvalidate.Code(code)] = sev
+	}
+	return res, nil
+}
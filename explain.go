@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/lenevi/go-magistr-lesson2-zhiltsovEA/podvalidate"
+)
+
+/*************** Explain ****************/
+var hints = map[podvalidate.Code]string{
+	podvalidate.CodeRequired:          "add the field; see the Pod spec reference for its expected shape",
+	podvalidate.CodeType:              "quote strings that look like numbers or booleans, and drop the quotes around numbers",
+	podvalidate.CodeUnsupported:       "use one of the values Kubernetes accepts for this field; values are case-sensitive",
+	podvalidate.CodeFormat:            "names are lowercase alphanumerics joined by '-' (container names by '_'), quantities look like 500m or 128Mi",
+	podvalidate.CodeRange:             "ports are 1..65535, counts and durations must not be negative, quantities must not be zero",
+	podvalidate.CodeDuplicate:         "names, ports and keys must be unique within their list or object",
+	podvalidate.CodeUnknownField:      "check the spelling and indentation, or drop -strict to allow extra fields",
+	podvalidate.CodeUnknownReference:  "declare the volume in spec.volumes or fix the name",
+	podvalidate.CodeConflict:          "keep exactly one of the alternatives",
+	podvalidate.CodeMissingLimits:     "set resources.limits so the container cannot use the whole node",
+	podvalidate.CodeLatestPullPolicy:  "pin a version tag or set imagePullPolicy: Always",
+	podvalidate.CodeDeprecated:        "switch to the form named in the message",
+	podvalidate.CodePrivilegedPort:    "use a hostPort of 1024 or above, or drop hostPort and use a Service",
+	podvalidate.CodeEmptyDocument:     "the file is empty; remove it, leave a comment in it or pass -allow-empty",
+	podvalidate.CodeEmptyResources:    "set resources.limits or resources.requests, or -ignore=EMPTY_RESOURCES when a LimitRange sets them",
+	podvalidate.CodeMisplacedResource: "move the quantity under resources.limits or resources.requests",
+}
+
+// hint returns the remediation hint for e, or "" when there is none.
+func hint(e *podvalidate.ValidationError, registry string) string {
+	if e.Code == podvalidate.CodeFormat && e.Field == "image" {
+		if registry == "" {
+			return "images look like name:tag or name@sha256:<digest>"
+		}
+		return "images must start with " + registry + "/ and include a tag or a sha256 digest"
+	}
+	return hints[e.Code]
+}
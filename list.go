@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lenevi/go-magistr-lesson2-zhiltsovEA/podvalidate"
+)
+
+/*************** List ****************/
+// listTargets prints the kind, namespace and name of every object in targets
+// without validating them, with "-" for a missing one. Only files that cannot
+// be read or parsed make the returned exit code fail.
+func listTargets(stdout, stderr io.Writer, targets []target) int {
+	code := exitOK
+	for _, t := range targets {
+		objs, err := inventory(t.path)
+		if err != nil {
+			printSystemError(stderr, t.name, err)
+			code = exitSystem
+			continue
+		}
+		for _, o := range objs {
+			name := o.Name
+			if o.Namespace != "" {
+				name = o.Namespace + "/" + name
+			}
+			fmt.Fprintf(stdout, "%s:%d %s %s\n", docName(t.name, o.Doc), o.Line, orDash(o.Kind), orDash(name))
+		}
+	}
+	return code
+}
+
+func inventory(path string) ([]podvalidate.Object, error) {
+	f, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return podvalidate.Inventory(f)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
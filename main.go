@@ -1,406 +1,451 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
-	"strconv"
+	"runtime/debug"
+	"slices"
+	"sort"
 	"strings"
 
-	"gopkg.in/yaml.v3"
+	"github.com/lenevi/go-magistr-lesson2-zhiltsovEA/podvalidate"
 )
 
-/*************** Validator ****************/
-type Validator struct {
-	file string
-	errs int
-}
-
-func (v *Validator) fail(line int, msg string, args ...any) {
-	fmt.Printf("%s:%d %s\n", v.file, line, fmt.Sprintf(msg, args...))
-	v.errs++
-}
-
-func (v *Validator) requiredField(node *yaml.Node, field string) (*yaml.Node, bool) {
-	m := mapify(node)
-	val, ok := m[field]
-	if !ok {
-		v.fail(node.Line, "%s is required", field)
-		return nil, false
-	}
-	return val, true
-}
-
-/*************** Helpers ****************/
-func mapify(n *yaml.Node) map[string]*yaml.Node {
-	res := make(map[string]*yaml.Node)
-	if n.Kind != yaml.MappingNode {
-		return res
-	}
-	for i := 0; i+1 < len(n.Content); i += 2 {
-		k := n.Content[i]
-		v := n.Content[i+1]
-		if k.Kind == yaml.ScalarNode {
-			res[k.Value] = v
-		}
-	}
-	return res
-}
+/*************** MAIN ****************/
+// Exit codes: a system error (bad usage, unreadable file, YAML syntax error)
+// wins over validation failures when both happen in one run.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitSystem  = 2
+)
 
-func isInt(node *yaml.Node) bool {
-	if node.Tag != "!!int" {
-		return false
-	}
-	_, err := strconv.Atoi(node.Value)
-	return err == nil
-}
+// Codes of the report entry for a file that could not be checked.
+const (
+	codeSyntax podvalidate.Code = "SYNTAX_ERROR"
+	codeSystem podvalidate.Code = "SYSTEM_ERROR"
+)
 
-func isString(node *yaml.Node) bool {
-	return node.Tag == "!!str"
+// version is set at build time with -ldflags "-X main.version=v1.2.3";
+// otherwise the module version from the build info is used.
+var version string
+
+type fileReport struct {
+	name string
+	path string
+	errs []*podvalidate.ValidationError
+	// counts holds how many times an error repeated, for those that did.
+	counts map[*podvalidate.ValidationError]int
+	// sysErr is the error that stopped the file from being checked. errs
+	// then holds it as a single entry.
+	sysErr error
 }
 
-var (
-	reSnake  = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
-	reImage  = regexp.MustCompile(`^registry\.bigbrother\.io\/[^:\s]+:[^:\s]+$`)
-	reMem    = regexp.MustCompile(`^[0-9]+(Gi|Mi|Ki)$`)
-	reAbs    = regexp.MustCompile(`^/`)
-	validOS  = map[string]bool{"linux": true, "windows": true}
-	validPro = map[string]bool{"TCP": true, "UDP": true}
-)
-
-/*************** MAIN ****************/
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintln(os.Stderr, "usage: yamlvalid <file>")
-		os.Exit(2)
+	opts := podvalidate.DefaultOptions()
+	flag.BoolVar(&opts.FailFast, "fail-fast", false, "stop at the first validation error")
+	flag.IntVar(&opts.MaxErrors, "max-errors", 0, "stop after this many validation errors per file, 0 for no limit")
+	flag.BoolVar(&opts.Strict, "strict", false, "reject unknown fields")
+	flag.StringVar(&opts.Registry, "registry", opts.Registry, "required image registry `host`, or host/path prefix, empty to allow any")
+	flag.BoolVar(&opts.AllowEmpty, "allow-empty", false, "only warn about empty files")
+	flag.BoolVar(&opts.Coerce, "coerce", false, "accept quoted integers such as \"8080\" where an int is expected")
+	flag.BoolVar(&opts.RequireResources, "require-resources", opts.RequireResources, "require resources on every container")
+	flag.Var((*listFlag)(&opts.APIVersions), "api-versions", "comma-separated `list` of accepted apiVersion values")
+	flag.Var((*listFlag)(&opts.AllowedOS), "allowed-os", "comma-separated `list` of accepted spec.os values")
+	flag.Var((*listFlag)(&opts.Kinds), "kind", "comma-separated `list` of kinds to validate, other documents are skipped")
+	flag.Var((*listFlag)(&opts.AllowedRepos), "allowed-repos", "comma-separated `list` of glob patterns, such as approved/*, for image repositories")
+	var severity []string
+	flag.Var((*listFlag)(&severity), "severity", "comma-separated `list` of CODE:warn or CODE:error severity overrides")
+	var ignore []string
+	flag.Var((*listFlag)(&ignore), "ignore", "comma-separated `list` of error codes to skip")
+	configPath := flag.String("config", "", "path to the config `file` (default "+defaultConfigFile+" if present)")
+	tagPattern := flag.String("tag-pattern", "", "`regexp` every image tag must match in full, e.g. v[0-9]+\\.[0-9]+\\.[0-9]+")
+	baselinePath := flag.String("baseline", "", "JSON `file` of known errors that do not fail the run")
+	updateBaseline := flag.Bool("update-baseline", false, "record the current errors in the -baseline file and exit")
+	schemaPath := flag.String("schema", "", "validate against this JSON Schema `file` instead of the built-in Pod rules")
+	input := flag.String("input", "yaml", "input format: yaml or json (json line numbers are approximate when minified)")
+	format := flag.String("format", "text", "output format: text, json, sarif, github or junit")
+	color := flag.String("color", "auto", "colorize text output: auto, always or never")
+	explain := flag.Bool("explain", false, "print a remediation hint under each text error")
+	columns := flag.Bool("columns", false, "print line:column locations in text output")
+	list := flag.Bool("list", false, "print the kind, namespace and name of every object instead of validating")
+	verbose := flag.Bool("verbose", false, "log the parts of each manifest as they are validated to stderr")
+	quiet := flag.Bool("quiet", false, "print nothing, report the result by exit code only")
+	exts := []string{".yaml", ".yml"}
+	flag.Var((*listFlag)(&exts), "ext", "comma-separated `list` of file extensions to pick up in directories")
+	strictWarnings := flag.Bool("warnings-as-errors", false, "fail on warnings too")
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: yamlvalid [flags] <file>...")
+		flag.PrintDefaults()
+	}
+	// Parse errors are held back until -quiet is known.
+	var parseOut bytes.Buffer
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	flag.CommandLine.SetOutput(&parseOut)
+	err := flag.CommandLine.Parse(os.Args[1:])
+
+	stdout, stderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	if *quiet {
+		stdout, stderr = io.Discard, io.Discard
+	}
+	flag.CommandLine.SetOutput(stderr)
+	stderr.Write(parseOut.Bytes())
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOK)
+	} else if err != nil {
+		os.Exit(exitSystem)
+	}
+
+	if *showVersion {
+		fmt.Println("yamlvalid", buildVersion())
+		os.Exit(exitOK)
+	}
+	for _, c := range ignore {
+		opts.IgnoreCodes = append(opts.IgnoreCodes, podvalidate.Code(c))
+	}
+
+	sevs, err := parseSeverities(severity)
+	if err != nil {
+		fmt.Fprintf(stderr, "severity: %v\n", err)
+		os.Exit(exitSystem)
 	}
+	opts.Severities = sevs
 
-	path := os.Args[1]
-	data, err := os.ReadFile(path)
+	cfg, err := loadConfig(*configPath)
+	if err == nil && cfg != nil {
+		set := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		err = cfg.apply(&opts, set)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(path), err)
-		os.Exit(2)
+		fmt.Fprintf(stderr, "config: %v\n", err)
+		os.Exit(exitSystem)
 	}
 
-	var root yaml.Node
-	if err := yaml.Unmarshal(data, &root); err != nil {
-		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(path), err)
-		os.Exit(2)
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(exitSystem)
 	}
-
-	v := &Validator{file: filepath.Base(path)}
-	v.validateRoot(&root)
-
-	if v.errs > 0 {
-		os.Exit(1)
+	if *input != "yaml" && *input != "json" {
+		fmt.Fprintf(stderr, "unsupported input '%s'\n", *input)
+		os.Exit(exitSystem)
 	}
-}
+	opts.JSON = *input == "json"
 
-/*************** Root ****************/
-func (v *Validator) validateRoot(root *yaml.Node) {
-	var doc *yaml.Node
-	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
-		doc = root.Content[0]
-	} else {
-		doc = root
+	for _, p := range opts.AllowedRepos {
+		if _, err := path.Match(p, ""); err != nil {
+			fmt.Fprintf(stderr, "allowed-repos: bad pattern '%s'\n", p)
+			os.Exit(exitSystem)
+		}
 	}
-
-	if doc.Kind != yaml.MappingNode {
-		v.fail(doc.Line, "top-level must be a mapping")
-		return
+	var base baseline
+	if *updateBaseline && *baselinePath == "" {
+		fmt.Fprintln(stderr, "update-baseline needs -baseline")
+		os.Exit(exitSystem)
 	}
-
-	m := mapify(doc)
-
-	// apiVersion
-	api, ok := m["apiVersion"]
-	if !ok {
-		v.fail(doc.Line, "apiVersion is required")
-	} else if !isString(api) {
-		v.fail(api.Line, "apiVersion must be string")
-	} else if api.Value != "v1" {
-		v.fail(api.Line, "apiVersion has unsupported value '%s'", api.Value)
+	if *baselinePath != "" && !*updateBaseline {
+		if base, err = loadBaseline(*baselinePath); err != nil {
+			fmt.Fprintf(stderr, "baseline: %v\n", err)
+			os.Exit(exitSystem)
+		}
 	}
-
-	// kind
-	kd, ok := m["kind"]
-	if !ok {
-		v.fail(doc.Line, "kind is required")
-	} else if !isString(kd) {
-		v.fail(kd.Line, "kind must be string")
-	} else if kd.Value != "Pod" {
-		v.fail(kd.Line, "kind has unsupported value '%s'", kd.Value)
+	if *tagPattern != "" {
+		re, err := regexp.Compile("^(?:" + *tagPattern + ")$")
+		if err != nil {
+			fmt.Fprintf(stderr, "tag-pattern: %v\n", err)
+			os.Exit(exitSystem)
+		}
+		opts.TagPattern = re
 	}
-
-	// metadata
-	meta, ok := m["metadata"]
-	if !ok {
-		v.fail(doc.Line, "metadata is required")
-	} else {
-		v.validateMetadata(meta)
+	if *schemaPath != "" {
+		data, err := os.ReadFile(*schemaPath)
+		if err == nil {
+			opts.Schema, err = podvalidate.ParseSchema(data)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "schema: %v\n", err)
+			os.Exit(exitSystem)
+		}
 	}
-
-	// spec
-	spec, ok := m["spec"]
-	if !ok {
-		v.fail(doc.Line, "spec is required")
-	} else {
-		v.validateSpec(spec)
+	if !slices.Contains(formats, *format) {
+		fmt.Fprintf(stderr, "unsupported format '%s'\n", *format)
+		os.Exit(exitSystem)
 	}
-}
-
-/*************** Metadata ****************/
-func (v *Validator) validateMetadata(node *yaml.Node) {
-	if node.Kind != yaml.MappingNode {
-		v.fail(node.Line, "metadata must be object")
-		return
+	if opts.MaxErrors < 0 {
+		fmt.Fprintln(stderr, "max-errors must not be negative")
+		os.Exit(exitSystem)
 	}
-	m := mapify(node)
-
-	// name
-	nm, ok := m["name"]
-	if !ok {
-		v.fail(node.Line, "name is required")
-	} else if !isString(nm) {
-		v.fail(nm.Line, "name must be string")
-	} else if strings.TrimSpace(nm.Value) == "" {
-		v.fail(nm.Line, "name is required")
+	if !slices.Contains(colorModes, *color) {
+		fmt.Fprintf(stderr, "unsupported color mode '%s'\n", *color)
+		os.Exit(exitSystem)
 	}
 
-	// namespace
-	if ns, ok := m["namespace"]; ok {
-		if !isString(ns) {
-			v.fail(ns.Line, "namespace must be string")
+	code, invalid, skipped := exitOK, 0, 0
+	targets := expandPaths(flag.Args(), exts, func(name string, err error) {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		code = exitSystem
+		skipped++
+	})
+
+	if *list {
+		if c := listTargets(stdout, stderr, targets); c != exitOK {
+			code = c
 		}
+		os.Exit(code)
 	}
 
-	// labels
-	if lbs, ok := m["labels"]; ok {
-		if lbs.Kind != yaml.MappingNode {
-			v.fail(lbs.Line, "labels must be object")
-		} else {
-			for i := 0; i+1 < len(lbs.Content); i += 2 {
-				k := lbs.Content[i]
-				val := lbs.Content[i+1]
-
-				if k.Tag != "!!str" {
-					v.fail(k.Line, "labels key must be string")
-				}
-				if val.Tag != "!!str" {
-					v.fail(val.Line, "labels value must be string")
-				}
+	var reports []fileReport
+	var recorded []baselineEntry
+	for _, t := range targets {
+		name, path := t.name, t.path
+		fileOpts := opts
+		if *verbose {
+			fileOpts.Log = log.New(stderr, name+": ", 0)
+		}
+		errs, err := run(path, fileOpts)
+		if err != nil {
+			code = exitSystem
+			invalid++
+			if *updateBaseline {
+				printSystemError(stderr, name, err)
+			} else {
+				reports = append(reports, fileReport{name: name, path: path, errs: []*podvalidate.ValidationError{systemError(err)}, sysErr: err})
+			}
+			continue
+		}
+
+		sortErrors(errs)
+		if *updateBaseline {
+			for _, e := range errs {
+				recorded = append(recorded, entryOf(path, e))
 			}
+			continue
 		}
-	}
-}
+		if base != nil {
+			errs = base.filter(path, errs)
+		}
+		errs, counts := dedupe(errs)
+		reports = append(reports, fileReport{name: name, path: path, errs: errs, counts: counts})
 
-/*************** Spec ****************/
-func (v *Validator) validateSpec(node *yaml.Node) {
-	if node.Kind != yaml.MappingNode {
-		v.fail(node.Line, "spec must be object")
-		return
+		if failed(errs, *strictWarnings) {
+			if code == exitOK {
+				code = exitInvalid
+			}
+			invalid++
+		}
 	}
-	m := mapify(node)
 
-	// os optional: scalar or object
-	if osn, ok := m["os"]; ok {
-		switch osn.Kind {
-		case yaml.ScalarNode:
-			if !validOS[osn.Value] {
-				v.fail(osn.Line, "os has unsupported value '%s'", osn.Value)
-			}
-		case yaml.MappingNode:
-			obj := mapify(osn)
-			n, ok := obj["name"]
-			if !ok {
-				v.fail(osn.Line, "name is required")
-			} else if !isString(n) {
-				v.fail(n.Line, "name must be string")
-			} else if !validOS[n.Value] {
-				v.fail(n.Line, "name has unsupported value '%s'", n.Value)
-			}
-		default:
-			v.fail(osn.Line, "os must be string or object")
+	if *updateBaseline {
+		if err := writeBaseline(*baselinePath, recorded); err != nil {
+			fmt.Fprintf(stderr, "baseline: %v\n", err)
+			os.Exit(exitSystem)
+		}
+		fmt.Fprintf(stderr, "%d error(s) recorded in %s\n", len(recorded), *baselinePath)
+		os.Exit(code)
+	}
+
+	switch *format {
+	case "json":
+		printJSON(stdout, reports)
+	case "sarif":
+		printSARIF(stdout, reports)
+	case "github":
+		printGitHub(stdout, reports)
+	case "junit":
+		printJUnit(stdout, reports)
+	default:
+		to := textOptions{columns: *columns, color: *color}
+		if *explain {
+			to.hint = func(e *podvalidate.ValidationError) string { return hint(e, opts.Registry) }
 		}
+		printText(stdout, stderr, reports, to)
 	}
 
-	// containers required
-	cn, ok := m["containers"]
-	if !ok {
-		v.fail(node.Line, "containers is required")
-		return
+	if *format == "text" {
+		if n := countErrors(reports); n > 0 {
+			fmt.Fprintf(stderr, "%d error(s) found\n", n)
+		}
 	}
-	if cn.Kind != yaml.SequenceNode {
-		v.fail(cn.Line, "containers must be array")
-		return
+	if total := len(targets) + skipped; total > 1 && invalid+skipped > 0 {
+		fmt.Fprintf(stderr, "%d of %d files invalid\n", invalid+skipped, total)
 	}
+	os.Exit(code)
+}
+
+// listFlag is a comma-separated flag value that replaces its default.
+type listFlag []string
 
-	for _, item := range cn.Content {
-		v.validateContainer(item)
+func (l *listFlag) String() string {
+	if l == nil {
+		return ""
 	}
+	return strings.Join(*l, ",")
 }
 
-/*************** Container ****************/
-func (v *Validator) validateContainer(node *yaml.Node) {
-	if node.Kind != yaml.MappingNode {
-		v.fail(node.Line, "container must be object")
-		return
-	}
-	m := mapify(node)
-
-	// name
-	nm, ok := m["name"]
-	if !ok {
-		v.fail(node.Line, "name is required")
-	} else if !isString(nm) {
-		v.fail(nm.Line, "name must be string")
-	} else if !reSnake.MatchString(nm.Value) {
-		v.fail(nm.Line, "name has invalid format '%s'", nm.Value)
-	}
-
-	// image
-	img, ok := m["image"]
-	if !ok {
-		v.fail(node.Line, "image is required")
-	} else if !isString(img) {
-		v.fail(img.Line, "image must be string")
-	} else if !reImage.MatchString(img.Value) {
-		v.fail(img.Line, "image has invalid format '%s'", img.Value)
-	}
-
-	// ports
-	if prt, ok := m["ports"]; ok {
-		if prt.Kind != yaml.SequenceNode {
-			v.fail(prt.Line, "ports must be array")
-		} else {
-			for _, el := range prt.Content {
-				v.validatePort(el)
-			}
+func (l *listFlag) Set(s string) error {
+	*l = nil
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
 		}
 	}
+	return nil
+}
 
-	// probes
-	if rp, ok := m["readinessProbe"]; ok {
-		v.validateProbe(rp)
+func buildVersion() string {
+	if version != "" {
+		return version
 	}
-	if lp, ok := m["livenessProbe"]; ok {
-		v.validateProbe(lp)
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
 	}
+	return "(devel)"
+}
 
-	// resources
-	res, ok := m["resources"]
-	if !ok {
-		v.fail(node.Line, "resources is required")
-		return
+func displayName(path string) string {
+	if path == "-" {
+		return "<stdin>"
 	}
-	v.validateResources(res)
+	return filepath.Base(path)
 }
 
-/*************** ContainerPort ****************/
-func (v *Validator) validatePort(node *yaml.Node) {
-	if node.Kind != yaml.MappingNode {
-		v.fail(node.Line, "ports item must be object")
-		return
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
 	}
-	m := mapify(node)
+	return os.Open(path)
+}
 
-	cp, ok := m["containerPort"]
-	if !ok {
-		v.fail(node.Line, "containerPort is required")
-	} else if !isInt(cp) {
-		v.fail(cp.Line, "containerPort must be int")
-	} else {
-		port, _ := strconv.Atoi(cp.Value)
-		if port <= 0 || port >= 65536 {
-			v.fail(cp.Line, "containerPort value out of range")
-		}
+func run(path string, opts podvalidate.Options) ([]*podvalidate.ValidationError, error) {
+	f, err := openInput(path)
+	if err != nil {
+		return nil, err
 	}
+	defer f.Close()
 
-	if proto, ok := m["protocol"]; ok {
-		if !isString(proto) {
-			v.fail(proto.Line, "protocol must be string")
-		} else if !validPro[proto.Value] {
-			v.fail(proto.Line, "protocol has unsupported value '%s'", proto.Value)
+	var res []*podvalidate.ValidationError
+	for _, err := range podvalidate.ValidateReader(f, opts) {
+		var ve *podvalidate.ValidationError
+		if !errors.As(err, &ve) {
+			return nil, err
 		}
+		res = append(res, ve)
 	}
+	return res, nil
 }
 
-/*************** Probe ****************/
-func (v *Validator) validateProbe(node *yaml.Node) {
-	if node.Kind != yaml.MappingNode {
-		v.fail(node.Line, "readinessProbe must be object")
-		return
+// printSystemError reports an error that stopped a file from being checked.
+func printSystemError(w io.Writer, name string, err error) {
+	var se *podvalidate.SyntaxError
+	if errors.As(err, &se) && se.Line > 0 {
+		fmt.Fprintf(w, "%s:%d: syntax error: %s\n", name, se.Line, se.Msg)
+	} else {
+		fmt.Fprintf(w, "%s: %v\n", name, err)
 	}
-	m := mapify(node)
+}
 
-	hg, ok := m["httpGet"]
-	if !ok {
-		v.fail(node.Line, "httpGet is required")
-		return
-	}
-	if hg.Kind != yaml.MappingNode {
-		v.fail(hg.Line, "httpGet must be object")
-		return
+// systemError turns an error that stopped a file from being checked into a
+// report entry, so every output format shows the file as failed.
+func systemError(err error) *podvalidate.ValidationError {
+	e := &podvalidate.ValidationError{Severity: podvalidate.SeverityError, Code: codeSystem, Message: err.Error()}
+	var se *podvalidate.SyntaxError
+	if errors.As(err, &se) {
+		e.Code, e.Message = codeSyntax, "syntax error: "+se.Msg
+		if se.Line > 0 {
+			line := se.Line
+			e.Line = &line
+		}
 	}
+	return e
+}
 
-	obj := mapify(hg)
-
-	p, ok := obj["path"]
-	if !ok {
-		v.fail(hg.Line, "path is required")
-	} else if !isString(p) {
-		v.fail(p.Line, "path must be string")
-	} else if !reAbs.MatchString(p.Value) {
-		v.fail(p.Line, "path has invalid format '%s'", p.Value)
+func failed(errs []*podvalidate.ValidationError, strictWarnings bool) bool {
+	for _, e := range errs {
+		if e.Severity == podvalidate.SeverityError || strictWarnings {
+			return true
+		}
 	}
+	return false
+}
 
-	prt, ok := obj["port"]
-	if !ok {
-		v.fail(hg.Line, "port is required")
-	} else if !isInt(prt) {
-		v.fail(prt.Line, "port must be int")
-	} else {
-		x, _ := strconv.Atoi(prt.Value)
-		if x <= 0 || x >= 65536 {
-			v.fail(prt.Line, "port value out of range")
+func countErrors(reports []fileReport) int {
+	n := 0
+	for _, r := range reports {
+		if r.sysErr != nil {
+			continue
+		}
+		for _, e := range r.errs {
+			if e.Severity == podvalidate.SeverityError {
+				n += max(r.counts[e], 1)
+			}
 		}
 	}
+	return n
 }
 
-/*************** Resources ****************/
-func (v *Validator) validateResources(node *yaml.Node) {
-	if node.Kind != yaml.MappingNode {
-		v.fail(node.Line, "resources must be object")
-		return
-	}
-	m := mapify(node)
+// sortErrors orders errs top to bottom within each document. Errors without
+// a position come first in their document; ties keep the validation order.
+func sortErrors(errs []*podvalidate.ValidationError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		a, b := errs[i], errs[j]
+		if a.Doc != b.Doc {
+			return a.Doc < b.Doc
+		}
+		if lineOf(a) != lineOf(b) {
+			return lineOf(a) < lineOf(b)
+		}
+		return columnOf(a) < columnOf(b)
+	})
+}
 
-	if lim, ok := m["limits"]; ok {
-		v.validateResKV("limits", lim)
+// dedupe drops errors repeating an earlier one with the same line and
+// message, which generated multi-document files tend to produce, and counts
+// the repeats.
+func dedupe(errs []*podvalidate.ValidationError) ([]*podvalidate.ValidationError, map[*podvalidate.ValidationError]int) {
+	type key struct {
+		line int
+		msg  string
+	}
+	first := make(map[key]*podvalidate.ValidationError)
+	counts := make(map[*podvalidate.ValidationError]int)
+	res := errs[:0]
+	for _, e := range errs {
+		k := key{lineOf(e), e.Message}
+		if f, ok := first[k]; ok {
+			counts[f]++
+			continue
+		}
+		first[k] = e
+		counts[e] = 1
+		res = append(res, e)
 	}
-	if req, ok := m["requests"]; ok {
-		v.validateResKV("requests", req)
+	for e, n := range counts {
+		if n == 1 {
+			delete(counts, e)
+		}
 	}
+	return res, counts
 }
 
-func (v *Validator) validateResKV(name string, node *yaml.Node) {
-	if node.Kind != yaml.MappingNode {
-		v.fail(node.Line, "%s must be object", name)
-		return
+func lineOf(e *podvalidate.ValidationError) int {
+	if e.Line == nil {
+		return 0
 	}
-	m := mapify(node)
+	return *e.Line
+}
 
-	if cpu, ok := m["cpu"]; ok {
-		if !isInt(cpu) {
-			v.fail(cpu.Line, "cpu must be int")
-		}
-	}
-	if mem, ok := m["memory"]; ok {
-		if !isString(mem) {
-			v.fail(mem.Line, "memory must be string")
-		} else if !reMem.MatchString(mem.Value) {
-			v.fail(mem.Line, "memory has invalid format '%s'", mem.Value)
-		}
+func columnOf(e *podvalidate.ValidationError) int {
+	if e.Column == nil {
+		return 0
 	}
+	return *e.Column
 }
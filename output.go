@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lenevi/go-magistr-lesson2-zhiltsovEA/podvalidate"
+)
+
+/*************** Output ****************/
+var formats = []string{"text", "json", "sarif", "github", "junit"}
+
+var colorModes = []string{"auto", "always", "never"}
+
+const (
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+	ansiReset  = "\x1b[0m"
+)
+
+type textOptions struct {
+	columns bool
+	color   string
+	// hint, when set, returns a remediation hint printed under each error.
+	hint func(*podvalidate.ValidationError) string
+}
+
+func printText(stdout, stderr io.Writer, reports []fileReport, to textOptions) {
+	colorOut, colorErr := useColor(stdout, to.color), useColor(stderr, to.color)
+	for _, r := range reports {
+		if r.sysErr != nil {
+			printSystemError(stderr, r.name, r.sysErr)
+			continue
+		}
+		for _, e := range r.errs {
+			w, prefix, colored, msgColor := stdout, "", colorOut, ansiRed
+			if e.Severity == podvalidate.SeverityWarning {
+				w, prefix, colored, msgColor = stderr, "warning: ", colorErr, ansiYellow
+			}
+			loc, msg := location(r.name, e, to.columns), e.Message
+			if colored {
+				loc, msg = ansiCyan+loc+ansiReset, msgColor+msg+ansiReset
+			}
+			if n := r.counts[e]; n > 1 {
+				msg += fmt.Sprintf(" (x%d)", n)
+			}
+			fmt.Fprintf(w, "%s%s %s\n", prefix, loc, msg)
+			if to.hint != nil {
+				if h := to.hint(e); h != "" {
+					fmt.Fprintf(w, "    hint: %s\n", h)
+				}
+			}
+		}
+	}
+}
+
+// useColor reports whether escape codes go to w. In auto mode that is only
+// when w is a terminal and NO_COLOR is not set, so pipes stay plain.
+func useColor(w io.Writer, mode string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// location renders name:line, or name:line:col when columns is set and the
+// column is known.
+func location(name string, e *podvalidate.ValidationError, columns bool) string {
+	name = docName(name, e.Doc)
+	if e.Line == nil {
+		return name + ":"
+	}
+	if columns && e.Column != nil {
+		return fmt.Sprintf("%s:%d:%d", name, *e.Line, *e.Column)
+	}
+	return fmt.Sprintf("%s:%d", name, *e.Line)
+}
+
+func docName(name string, doc int) string {
+	if doc == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s[doc %d]", name, doc)
+}
+
+type jsonError struct {
+	File     string `json:"file"`
+	Doc      int    `json:"doc,omitempty"`
+	Line     *int   `json:"line,omitempty"`
+	Column   *int   `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Field    string `json:"field,omitempty"`
+	Count    int    `json:"count,omitempty"`
+}
+
+func printJSON(w io.Writer, reports []fileReport) {
+	out := []jsonError{}
+	for _, r := range reports {
+		for _, e := range r.errs {
+			out = append(out, jsonError{File: r.name, Doc: e.Doc, Line: e.Line, Column: e.Column, Severity: e.Severity.String(), Code: string(e.Code), Message: e.Message, Field: e.Field, Count: r.counts[e]})
+		}
+	}
+	writeJSON(w, out)
+}
+
+func writeJSON(w io.Writer, v any) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+/*************** SARIF ****************/
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int  `json:"startLine"`
+	StartColumn *int `json:"startColumn,omitempty"`
+}
+
+// ruleID turns a code such as INVALID_FORMAT into invalid-format.
+func ruleID(c podvalidate.Code) string {
+	return strings.ToLower(strings.ReplaceAll(string(c), "_", "-"))
+}
+
+func printSARIF(w io.Writer, reports []fileReport) {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "yamlvalid", Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	seen := make(map[string]bool)
+	for _, r := range reports {
+		for _, e := range r.errs {
+			id := ruleID(e.Code)
+			if !seen[id] {
+				seen[id] = true
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id})
+			}
+
+			loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: filepath.ToSlash(r.path)}}
+			if e.Line != nil {
+				loc.Region = &sarifRegion{StartLine: *e.Line, StartColumn: e.Column}
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    id,
+				Level:     e.Severity.String(),
+				Message:   sarifMessage{Text: e.Message},
+				Locations: []sarifLocation{{PhysicalLocation: loc}},
+			})
+		}
+	}
+
+	writeJSON(w, sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
+
+/*************** GitHub ****************/
+// printGitHub writes GitHub Actions workflow commands, which the runner shows
+// as annotations on the changed lines.
+func printGitHub(w io.Writer, reports []fileReport) {
+	for _, r := range reports {
+		for _, e := range r.errs {
+			params := "file=" + ghEscapeProperty(filepath.ToSlash(r.path))
+			if e.Line != nil {
+				params += fmt.Sprintf(",line=%d", *e.Line)
+				if e.Column != nil {
+					params += fmt.Sprintf(",col=%d", *e.Column)
+				}
+			}
+			params += ",title=" + ghEscapeProperty(string(e.Code))
+			fmt.Fprintf(w, "::%s %s::%s\n", e.Severity, params, ghEscapeData(e.Message))
+		}
+	}
+}
+
+var (
+	ghDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	ghPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func ghEscapeData(s string) string     { return ghDataEscaper.Replace(s) }
+func ghEscapeProperty(s string) string { return ghPropertyEscaper.Replace(s) }
+
+/*************** JUnit ****************/
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string         `xml:"name,attr"`
+	Classname string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// printJUnit writes one test case per file and one failure per error.
+// Warnings do not fail a case and are listed in its system-out instead.
+func printJUnit(w io.Writer, reports []fileReport) {
+	suite := junitSuite{Name: "yamlvalid", Tests: len(reports)}
+	for _, r := range reports {
+		tc := junitCase{Name: filepath.ToSlash(r.path), Classname: "yamlvalid"}
+		for _, e := range r.errs {
+			loc := location(r.name, e, true)
+			if e.Severity == podvalidate.SeverityWarning {
+				tc.SystemOut += "warning: " + loc + " " + e.Message + "\n"
+				continue
+			}
+			tc.Failures = append(tc.Failures, junitFailure{Message: e.Message, Type: string(e.Code), Text: loc + " " + e.Message})
+		}
+		if len(tc.Failures) > 0 {
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	fmt.Fprintln(w)
+}
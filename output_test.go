@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lenevi/go-magistr-lesson2-zhiltsovEA/podvalidate"
+)
+
+// testReports returns a report for a valid file, one for a file with a
+// syntax error on line 3 and one for a file that could not be read.
+func testReports() []fileReport {
+	reports := []fileReport{{name: "ok.yaml", path: "dir/ok.yaml"}}
+	for _, f := range []struct {
+		name string
+		err  error
+	}{
+		{"bad.yaml", &podvalidate.SyntaxError{Line: 3, Msg: "did not find expected key"}},
+		{"gone.yaml", errors.New("open dir/gone.yaml: permission denied")},
+	} {
+		reports = append(reports, fileReport{name: f.name, path: "dir/" + f.name, errs: []*podvalidate.ValidationError{systemError(f.err)}, sysErr: f.err})
+	}
+	return reports
+}
+
+func TestPrintJUnitSystemErrors(t *testing.T) {
+	var sb strings.Builder
+	printJUnit(&sb, testReports())
+	out := sb.String()
+	for _, want := range []string{
+		`<testsuite name="yamlvalid" tests="3" failures="2">`,
+		`<failure message="syntax error: did not find expected key" type="SYNTAX_ERROR">bad.yaml:3 syntax error: did not find expected key</failure>`,
+		`<failure message="open dir/gone.yaml: permission denied" type="SYSTEM_ERROR">gone.yaml: open dir/gone.yaml: permission denied</failure>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintTextSystemErrors(t *testing.T) {
+	var stdout, stderr strings.Builder
+	reports := testReports()
+	printText(&stdout, &stderr, reports, textOptions{color: "never"})
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want empty", stdout.String())
+	}
+	want := "bad.yaml:3: syntax error: did not find expected key\ngone.yaml: open dir/gone.yaml: permission denied\n"
+	if stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+	if n := countErrors(reports); n != 0 {
+		t.Errorf("countErrors = %d, want 0", n)
+	}
+}
+
+func TestPrintJSONSystemErrors(t *testing.T) {
+	var sb strings.Builder
+	printJSON(&sb, testReports())
+	var got []jsonError
+	if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2:\n%s", len(got), sb.String())
+	}
+	if e := got[0]; e.File != "bad.yaml" || e.Code != "SYNTAX_ERROR" || e.Severity != "error" || e.Line == nil || *e.Line != 3 {
+		t.Errorf("syntax error entry = %+v", e)
+	}
+	if e := got[1]; e.File != "gone.yaml" || e.Code != "SYSTEM_ERROR" || e.Line != nil {
+		t.Errorf("read error entry = %+v", e)
+	}
+}
+
+func TestPrintSARIFSystemErrors(t *testing.T) {
+	var sb strings.Builder
+	printSARIF(&sb, testReports())
+	var got sarifLog
+	if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	res := got.Runs[0].Results
+	if len(res) != 2 {
+		t.Fatalf("got %d results, want 2:\n%s", len(res), sb.String())
+	}
+	loc := res[0].Locations[0].PhysicalLocation
+	if res[0].RuleID != "syntax-error" || res[0].Level != "error" || loc.ArtifactLocation.URI != "dir/bad.yaml" || loc.Region == nil || loc.Region.StartLine != 3 {
+		t.Errorf("syntax error result = %+v", res[0])
+	}
+	if res[1].RuleID != "system-error" || res[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("read error result = %+v", res[1])
+	}
+}
+
+func TestPrintGitHubSystemErrors(t *testing.T) {
+	var sb strings.Builder
+	printGitHub(&sb, testReports())
+	want := "::error file=dir/bad.yaml,line=3,title=SYNTAX_ERROR::syntax error: did not find expected key\n" +
+		"::error file=dir/gone.yaml,title=SYSTEM_ERROR::open dir/gone.yaml: permission denied\n"
+	if sb.String() != want {
+		t.Errorf("got %q, want %q", sb.String(), want)
+	}
+}
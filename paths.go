@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+/*************** Paths ****************/
+// target is a single input to validate. Files found by walking a directory
+// or matching a glob pattern are named by their path, so equal base names in
+// different folders stay apart; files given explicitly keep their base name.
+type target struct {
+	path string
+	name string
+}
+
+// expandPaths turns the command line arguments into targets. A directory, or
+// a symlink to one, is walked recursively for files whose extension is in
+// exts; symlinks inside it are not followed, so a link back to a parent cannot
+// cause a loop. A directory without such files is reported like a pattern
+// that matches nothing. An
+// argument that does not exist but holds a glob pattern is expanded with
+// filepath.Glob, since not every shell does it. Arguments that cannot be
+// expanded are reported through onErr and skipped.
+func expandPaths(args, exts []string, onErr func(name string, err error)) []target {
+	var res []target
+	var add func(arg, name string)
+	add = func(arg, name string) {
+		info, err := os.Stat(arg)
+		if err != nil && arg != "-" && strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				onErr(arg, err)
+			} else if len(matches) == 0 {
+				onErr(arg, errors.New("no files match the pattern"))
+			}
+			for _, m := range matches {
+				add(m, m)
+			}
+			return
+		}
+		if err != nil || !info.IsDir() {
+			// A missing file is reported when it is read.
+			res = append(res, target{path: arg, name: name})
+			return
+		}
+
+		root, err := filepath.EvalSymlinks(arg)
+		if err != nil {
+			onErr(arg, err)
+			return
+		}
+		found := len(res)
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			// Name files by the argument, not by where a symlink points.
+			if rel, relErr := filepath.Rel(root, path); relErr == nil {
+				path = filepath.Join(arg, rel)
+			}
+			if err != nil {
+				onErr(path, err)
+				return nil
+			}
+			if d.Type().IsRegular() && hasExt(path, exts) {
+				res = append(res, target{path: path, name: path})
+			}
+			return nil
+		})
+		if err != nil {
+			onErr(arg, err)
+		} else if len(res) == found {
+			onErr(arg, errors.New("no files with a matching extension in the directory"))
+		}
+	}
+
+	for _, arg := range args {
+		add(arg, displayName(arg))
+	}
+	return res
+}
+
+func hasExt(path string, exts []string) bool {
+	ext := filepath.Ext(path)
+	return ext != "" && slices.ContainsFunc(exts, func(e string) bool {
+		return strings.EqualFold(ext, "."+strings.TrimPrefix(e, "."))
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"pods/a.yaml", "pods/sub/b.yml", "pods/notes.txt", "docs/readme.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "pods"), filepath.Join(dir, "link")); err != nil {
+		t.Skip(err)
+	}
+
+	tests := []struct {
+		name string
+		arg  string
+		want []string
+		errs []string
+	}{
+		{name: "directory", arg: "pods", want: []string{"pods/a.yaml", "pods/sub/b.yml"}},
+		{name: "symlinked directory", arg: "link", want: []string{"link/a.yaml", "link/sub/b.yml"}},
+		{name: "empty directory", arg: "empty", errs: []string{"empty"}},
+		{name: "no matching files", arg: "docs", errs: []string{"docs"}},
+		{name: "unmatched pattern", arg: "*.json", errs: []string{"*.json"}},
+		{name: "file", arg: "pods/notes.txt", want: []string{"pods/notes.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []string
+			targets := expandPaths([]string{filepath.Join(dir, tt.arg)}, []string{"yaml", "yml"}, func(name string, err error) {
+				rel, _ := filepath.Rel(dir, name)
+				errs = append(errs, filepath.ToSlash(rel))
+			})
+			var got []string
+			for _, tg := range targets {
+				rel, _ := filepath.Rel(dir, tg.path)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if !slices.Equal(got, tt.want) || !slices.Equal(errs, tt.errs) {
+				t.Errorf("got %q, errors %q; want %q, errors %q", got, errs, tt.want, tt.errs)
+			}
+		})
+	}
+}
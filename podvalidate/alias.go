@@ -0,0 +1,125 @@
+package podvalidate
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+/*************** Aliases ****************/
+// maxExpandedNodes bounds the size of a document once its aliases are
+// expanded, so a few nested aliases cannot make validation run forever.
+const maxExpandedNodes = 1 << 20
+
+// resolveAliases replaces every alias under root with a copy of the node it
+// refers to, placed at the alias position, so the rules never meet an
+// AliasNode. The copy shares its children with the anchored node. Merge keys
+// (<<) are expanded as well.
+func resolveAliases(root *yaml.Node) error {
+	if _, err := expandedSize(root, make(map[*yaml.Node]int)); err != nil {
+		return err
+	}
+	resolve(root, make(map[*yaml.Node]bool))
+	return nil
+}
+
+func resolve(n *yaml.Node, seen map[*yaml.Node]bool) {
+	if seen[n] {
+		return
+	}
+	seen[n] = true
+	for i, c := range n.Content {
+		if c.Kind == yaml.AliasNode && c.Alias != nil {
+			resolve(c.Alias, seen)
+			cp := *c.Alias
+			cp.Anchor = ""
+			cp.Line, cp.Column = c.Line, c.Column
+			n.Content[i] = &cp
+			continue
+		}
+		resolve(c, seen)
+	}
+	if n.Kind == yaml.MappingNode {
+		expandMerge(n)
+	}
+}
+
+// expandMerge replaces the << keys of a mapping with the pairs they merge in.
+// Keys set on the mapping itself win, then earlier merged mappings win over
+// later ones, as in the YAML merge key spec.
+func expandMerge(n *yaml.Node) {
+	hasMerge := false
+	explicit := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if k := n.Content[i]; isMergeKey(k) {
+			hasMerge = true
+		} else {
+			explicit[k.Value] = true
+		}
+	}
+	if !hasMerge {
+		return
+	}
+
+	merged := make(map[string]bool)
+	content := make([]*yaml.Node, 0, len(n.Content))
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, val := n.Content[i], n.Content[i+1]
+		if !isMergeKey(k) {
+			content = append(content, k, val)
+			continue
+		}
+
+		sources := []*yaml.Node{val}
+		if val.Kind == yaml.SequenceNode {
+			sources = val.Content
+		}
+		for _, src := range sources {
+			if src.Kind != yaml.MappingNode {
+				// Left for the rules to report as an unknown key.
+				content = append(content, k, val)
+				break
+			}
+			for j := 0; j+1 < len(src.Content); j += 2 {
+				mk := src.Content[j]
+				if explicit[mk.Value] || merged[mk.Value] {
+					continue
+				}
+				merged[mk.Value] = true
+				content = append(content, mk, src.Content[j+1])
+			}
+		}
+	}
+	n.Content = content
+}
+
+func isMergeKey(k *yaml.Node) bool {
+	return k.Kind == yaml.ScalarNode && k.Tag == "!!merge" && k.Value == "<<"
+}
+
+// expandedSize counts the nodes of n with aliases expanded. It fails when
+// the count exceeds maxExpandedNodes or an alias refers to a node that
+// contains it.
+func expandedSize(n *yaml.Node, memo map[*yaml.Node]int) (int, error) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		if size, ok := memo[n.Alias]; ok && size < 0 {
+			return 0, &SyntaxError{Line: n.Line, Msg: "alias '" + n.Value + "' refers to a node that contains it"}
+		}
+		n = n.Alias
+	}
+	if size, ok := memo[n]; ok {
+		return size, nil
+	}
+	memo[n] = -1
+
+	size := 1
+	for _, c := range n.Content {
+		cs, err := expandedSize(c, memo)
+		if err != nil {
+			return 0, err
+		}
+		if size += cs; size > maxExpandedNodes {
+			return 0, &SyntaxError{Msg: "document expands to too many nodes through aliases"}
+		}
+	}
+	memo[n] = size
+	return size, nil
+}
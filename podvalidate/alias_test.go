@@ -0,0 +1,94 @@
+package podvalidate
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "anchored container",
+			src: `apiVersion: v1
+kind: Pod
+metadata:
+  name: a
+  labels: &labels
+    app: web
+spec:
+  nodeSelector: *labels
+  containers:
+    - &web
+      name: web
+      image: registry.bigbrother.io/app:1.0
+      resources:
+        limits: &limits {cpu: 500m, memory: 128Mi}
+    - <<: *web
+      name: side
+    - <<: [*web, {tty: true}]
+      name: debug
+      resources: {limits: *limits, requests: *limits}
+`,
+		},
+		{
+			name: "errors in anchored nodes",
+			src: `apiVersion: v1
+kind: Pod
+metadata:
+  name: a
+spec:
+  containers:
+    - &web
+      name: web
+      image: registry.bigbrother.io/app:1.0
+      ports:
+        - containerPort: 0
+      resources: {limits: {cpu: 1}}
+    - <<: *web
+      name: side
+      tty: 5
+    - *web
+`,
+			want: []string{
+				"11 containerPort value out of range",
+				"11 containerPort value out of range",
+				"15 tty must be bool",
+				"11 containerPort value out of range",
+				"8 duplicate container name 'web' in containers, first defined in containers",
+			},
+		},
+		{
+			name: "merge of a scalar",
+			src: `apiVersion: v1
+kind: Pod
+metadata:
+  name: a
+spec:
+  containers:
+    - <<: 5
+      name: web
+      image: registry.bigbrother.io/app:1.0
+      resources: {limits: {cpu: 1}}
+`,
+			want: []string{"7 unknown field '<<'"},
+		},
+		{
+			name: "recursive alias",
+			src:  "a: &a\n  b: *a\n",
+			want: []string{"line 2: syntax error: alias 'a' refers to a node that contains it"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Strict = true
+			if got := messages(t, tt.src, opts); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
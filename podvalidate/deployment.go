@@ -0,0 +1,73 @@
+package podvalidate
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+/*************** Deployment ****************/
+func (v *validator) validateDeployment(doc *yaml.Node, m map[string]*yaml.Node) {
+	// metadata
+	meta, ok := m["metadata"]
+	if !ok {
+		v.required(doc, "metadata")
+	} else {
+		v.validateMetadata(meta, true)
+	}
+
+	// spec
+	spec, ok := m["spec"]
+	if !ok {
+		v.required(doc, "spec")
+		return
+	}
+	v.validateDeploymentSpec(spec)
+}
+
+func (v *validator) validateDeploymentSpec(node *yaml.Node) {
+	defer v.enter("spec")()
+	if node.Kind != yaml.MappingNode {
+		v.mustBe(node, "spec", "object")
+		return
+	}
+	m := v.mapify(node)
+	v.checkUnknownFields(node, "replicas", "selector", "template")
+
+	// replicas
+	if r, ok := m["replicas"]; ok {
+		v.validateIntMin(r, "replicas", 0)
+	}
+
+	// selector
+	sel, ok := m["selector"]
+	if !ok {
+		v.required(node, "selector")
+	} else if sel.Kind != yaml.MappingNode {
+		v.mustBe(sel, "selector", "object")
+	} else if ml, ok := v.mapify(sel)["matchLabels"]; ok {
+		v.validateStringMap(ml, "matchLabels")
+	}
+
+	// template
+	tpl, ok := m["template"]
+	if !ok {
+		v.required(node, "template")
+		return
+	}
+	if tpl.Kind != yaml.MappingNode {
+		v.mustBe(tpl, "template", "object")
+		return
+	}
+	tm := v.mapify(tpl)
+	defer v.enter("template")()
+
+	if meta, ok := tm["metadata"]; ok {
+		v.validateMetadata(meta, false)
+	}
+
+	spec, ok := tm["spec"]
+	if !ok {
+		v.required(tpl, "spec")
+		return
+	}
+	v.validateSpec(spec)
+}
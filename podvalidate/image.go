@@ -0,0 +1,67 @@
+package podvalidate
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+/*************** Image ****************/
+var (
+	reRegistry  = regexp.MustCompile(`^(localhost|[A-Za-z0-9]([-A-Za-z0-9]*[A-Za-z0-9])?(\.[A-Za-z0-9]([-A-Za-z0-9]*[A-Za-z0-9])?)*)(:[0-9]+)?$`)
+	reRepoPart  = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*$`)
+	reImageTag  = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	reDigest    = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+	errImageRef = errors.New("invalid image reference")
+)
+
+// parseImageRef splits an image reference such as
+// registry.example.com:5000/team/app:1.0@sha256:<hex> into its parts. The
+// first path component is the registry only when it looks like a host: it
+// holds a '.' or a ':' or is localhost. A tag is only looked for after the
+// last '/', so a registry port is never taken for one.
+func parseImageRef(s string) (registry, repo, tag, digest string, err error) {
+	name := s
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+		if !reDigest.MatchString(digest) {
+			return "", "", "", "", errImageRef
+		}
+	}
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		name, tag = name[:i], name[i+1:]
+		if !reImageTag.MatchString(tag) {
+			return "", "", "", "", errImageRef
+		}
+	}
+
+	if host, rest, ok := strings.Cut(name, "/"); ok &&
+		(strings.ContainsAny(host, ".:") || host == "localhost") {
+		if !reRegistry.MatchString(host) {
+			return "", "", "", "", errImageRef
+		}
+		registry, name = host, rest
+	}
+	for _, part := range strings.Split(name, "/") {
+		if !reRepoPart.MatchString(part) {
+			return "", "", "", "", errImageRef
+		}
+	}
+	return registry, name, tag, digest, nil
+}
+
+// registryMatches reports whether the image at registry and repo is under
+// required, a registry host optionally followed by a path such as
+// gcr.io/myproj. A required host given without a port accepts that host on
+// any port.
+func registryMatches(registry, repo, required string) bool {
+	reqHost, reqPath, hasPath := strings.Cut(strings.TrimSuffix(required, "/"), "/")
+	if hasPath && !strings.HasPrefix(repo, reqPath+"/") {
+		return false
+	}
+	if registry == reqHost {
+		return true
+	}
+	host, _, hasPort := strings.Cut(registry, ":")
+	return hasPort && !strings.Contains(reqHost, ":") && host == reqHost
+}
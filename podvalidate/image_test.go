@@ -0,0 +1,104 @@
+package podvalidate
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseImageRef(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	tests := []struct {
+		in                          string
+		registry, repo, tag, digest string
+		ok                          bool
+	}{
+		{in: "nginx", repo: "nginx", ok: true},
+		{in: "nginx:1.25", repo: "nginx", tag: "1.25", ok: true},
+		{in: "library/nginx:1.25", repo: "library/nginx", tag: "1.25", ok: true},
+		{in: "registry.bigbrother.io/app:1.0", registry: "registry.bigbrother.io", repo: "app", tag: "1.0", ok: true},
+		{in: "registry.bigbrother.io:5000/app:1.0", registry: "registry.bigbrother.io:5000", repo: "app", tag: "1.0", ok: true},
+		{in: "registry.bigbrother.io:5000/app", registry: "registry.bigbrother.io:5000", repo: "app", ok: true},
+		{in: "registry.bigbrother.io:5000/team/sub/app:v2", registry: "registry.bigbrother.io:5000", repo: "team/sub/app", tag: "v2", ok: true},
+		{in: "localhost/app:dev", registry: "localhost", repo: "app", tag: "dev", ok: true},
+		{in: "localhost:5000/app", registry: "localhost:5000", repo: "app", ok: true},
+		{in: "app@" + digest, repo: "app", digest: digest, ok: true},
+		{in: "registry.bigbrother.io:5000/app:1.0@" + digest, registry: "registry.bigbrother.io:5000", repo: "app", tag: "1.0", digest: digest, ok: true},
+		{in: ""},
+		{in: "App:1.0"},
+		{in: "app:"},
+		{in: "app:-1"},
+		{in: "app@sha256:abc"},
+		{in: "registry.bigbrother.io:port/app:1.0"},
+		{in: "registry.bigbrother.io/"},
+		{in: "registry.bigbrother.io//app"},
+	}
+	for _, tt := range tests {
+		registry, repo, tag, digest, err := parseImageRef(tt.in)
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("parseImageRef(%q) error = %v, want ok %v", tt.in, err, tt.ok)
+			continue
+		}
+		if registry != tt.registry || repo != tt.repo || tag != tt.tag || digest != tt.digest {
+			t.Errorf("parseImageRef(%q) = %q, %q, %q, %q; want %q, %q, %q, %q",
+				tt.in, registry, repo, tag, digest, tt.registry, tt.repo, tt.tag, tt.digest)
+		}
+	}
+}
+
+func TestValidateImageRegistryPort(t *testing.T) {
+	tests := []struct {
+		image string
+		ok    bool
+	}{
+		{"registry.bigbrother.io/app:1.0", true},
+		{"registry.bigbrother.io:5000/app:1.0", true},
+		{"registry.bigbrother.io:5000/team/app:1.0", true},
+		{"registry.bigbrother.io/app", false},
+		{"registry.bigbrother.io:5000/app", false},
+		{"registry.bigbrother.io:5000", false},
+		{"other.io:5000/app:1.0", false},
+		{"app:1.0", false},
+	}
+	for _, tt := range tests {
+		src := strings.Replace(validPod, "registry.bigbrother.io/app:1.0", tt.image, 1)
+		got := messages(t, src, DefaultOptions())
+		want := []string(nil)
+		if !tt.ok {
+			want = []string{"8 image has invalid format '" + tt.image + "'"}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: got %q, want %q", tt.image, got, want)
+		}
+	}
+}
+
+func TestValidateImageRegistryPrefix(t *testing.T) {
+	tests := []struct {
+		image string
+		ok    bool
+	}{
+		{"gcr.io/myproj/app:1.0", true},
+		{"gcr.io/myproj/team/app:1.0", true},
+		{"gcr.io:443/myproj/app:1.0", true},
+		{"gcr.io/myproj:1.0", false},
+		{"gcr.io/myproject/app:1.0", false},
+		{"gcr.io/other/app:1.0", false},
+		{"docker.io/myproj/app:1.0", false},
+	}
+	for _, prefix := range []string{"gcr.io/myproj", "gcr.io/myproj/"} {
+		opts := DefaultOptions()
+		opts.Registry = prefix
+		for _, tt := range tests {
+			src := strings.Replace(validPod, "registry.bigbrother.io/app:1.0", tt.image, 1)
+			got := messages(t, src, opts)
+			want := []string(nil)
+			if !tt.ok {
+				want = []string{"8 image has invalid format '" + tt.image + "'"}
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("%s %s: got %q, want %q", prefix, tt.image, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package podvalidate
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*************** Inventory ****************/
+// Object is a manifest object as seen before validation. Fields missing from
+// the manifest, or not strings, are left empty. Doc follows the numbering of
+// ValidationError.Doc.
+type Object struct {
+	Doc       int
+	Line      int
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// Inventory lists the objects in r without validating them; the items of a
+// List are listed in its place. Only read and syntax errors are returned.
+func Inventory(r io.Reader) ([]Object, error) {
+	var objs []Object
+	n, _, err := decodeDocuments(r, func(doc int, root *yaml.Node) {
+		objs = appendObjects(objs, doc, root.Content[0])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 1 {
+		for i := range objs {
+			objs[i].Doc = 0
+		}
+	}
+	return objs, nil
+}
+
+func appendObjects(objs []Object, doc int, n *yaml.Node) []Object {
+	obj := Object{
+		Doc:       doc,
+		Line:      n.Line,
+		Kind:      stringAt(n, "kind"),
+		Name:      stringAt(n, "metadata", "name"),
+		Namespace: stringAt(n, "metadata", "namespace"),
+	}
+	items, ok := nodeAtPath(n, []string{"items"})
+	if obj.Kind != "List" || !ok || items.Kind != yaml.SequenceNode {
+		return append(objs, obj)
+	}
+	for _, it := range items.Content {
+		objs = appendObjects(objs, doc, it)
+	}
+	return objs
+}
+
+func stringAt(n *yaml.Node, path ...string) string {
+	if s, ok := nodeAtPath(n, path); ok && isString(s) {
+		return s.Value
+	}
+	return ""
+}
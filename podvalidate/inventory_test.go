@@ -0,0 +1,39 @@
+package podvalidate
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInventory(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Object
+	}{
+		{name: "pod", src: validPod, want: []Object{{Line: 1, Kind: "Pod", Name: "my-pod"}}},
+		{name: "stream", src: "kind: A\n---\n# skip\n---\nkind: B\nmetadata: {name: b, namespace: ns}\n", want: []Object{
+			{Doc: 1, Line: 1, Kind: "A"},
+			{Doc: 2, Line: 5, Kind: "B", Name: "b", Namespace: "ns"},
+		}},
+		{name: "list", src: "kind: List\nitems:\n  - kind: Pod\n  - kind: Service\n", want: []Object{
+			{Line: 3, Kind: "Pod"},
+			{Line: 4, Kind: "Service"},
+		}},
+		{name: "empty", src: ""},
+		{name: "tab", src: "\t\n"},
+		{name: "comment and tab", src: "# placeholder\n\t\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Inventory(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package podvalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*************** JSON ****************/
+// maxJSONDepth matches the nesting limit of encoding/json.
+const maxJSONDepth = 10000
+
+// decodeJSON is decodeDocuments for JSON input, which holds exactly one
+// document and is read in full.
+func decodeJSON(r io.Reader, fn func(doc int, root *yaml.Node)) (n int, read bool, err error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return 0, len(content) > 0, err
+	}
+	root, err := parseJSON(content)
+	if err != nil {
+		return 0, len(content) > 0, err
+	}
+	fn(1, root)
+	return 1, true, nil
+}
+
+// jsonParser builds the node tree of a JSON document from the token stream
+// of encoding/json. JSON input never reaches the YAML parser, which rejects
+// some valid JSON such as escaped surrogate pairs.
+type jsonParser struct {
+	src   []byte
+	dec   *json.Decoder
+	lines []int // offsets at which the lines of src start
+}
+
+// parseJSON returns the document node of content. Every failure is a
+// *SyntaxError.
+func parseJSON(content []byte) (*yaml.Node, error) {
+	p := &jsonParser{src: content, dec: json.NewDecoder(bytes.NewReader(content)), lines: []int{0}}
+	p.dec.UseNumber()
+	for i, c := range content {
+		if c == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+
+	n, err := p.value(0)
+	if err != nil {
+		return nil, err
+	}
+	start := p.next()
+	if _, err := p.dec.Token(); err != io.EOF {
+		if err == nil {
+			line, _ := p.position(start)
+			return nil, &SyntaxError{Line: line, Msg: "invalid character after top-level value"}
+		}
+		return nil, p.syntaxError(err)
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{n}}, nil
+}
+
+func (p *jsonParser) value(depth int) (*yaml.Node, error) {
+	start := p.next()
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, p.syntaxError(err)
+	}
+	line, col := p.position(start)
+	n := &yaml.Node{Kind: yaml.ScalarNode, Line: line, Column: col}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t != '{' && t != '[' {
+			return nil, &SyntaxError{Line: line, Msg: "invalid character '" + t.String() + "' looking for beginning of value"}
+		}
+		if depth == maxJSONDepth {
+			return nil, &SyntaxError{Line: line, Msg: "exceeded max depth"}
+		}
+		n.Kind, n.Tag = yaml.SequenceNode, "!!seq"
+		if t == '{' {
+			n.Kind, n.Tag = yaml.MappingNode, "!!map"
+		}
+		for p.dec.More() {
+			el, err := p.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, el)
+		}
+		// The closing delimiter.
+		if _, err := p.dec.Token(); err != nil {
+			return nil, p.syntaxError(err)
+		}
+	case string:
+		n.Tag, n.Value, n.Style = "!!str", t, yaml.DoubleQuotedStyle
+	case json.Number:
+		n.Tag, n.Value = "!!int", t.String()
+		if strings.ContainsAny(n.Value, ".eE") {
+			n.Tag = "!!float"
+		}
+	case bool:
+		n.Tag, n.Value = "!!bool", strconv.FormatBool(t)
+	case nil:
+		n.Tag, n.Value = "!!null", "null"
+	}
+	return n, nil
+}
+
+// next returns the offset at which the next token starts.
+func (p *jsonParser) next() int {
+	off := int(p.dec.InputOffset())
+	for off < len(p.src) && strings.IndexByte(" \t\r\n,:", p.src[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+// position turns an offset into a 1-based line and column.
+func (p *jsonParser) position(off int) (line, col int) {
+	line = sort.SearchInts(p.lines, off+1)
+	start := p.lines[line-1]
+	return line, utf8.RuneCount(p.src[start:min(off, len(p.src))]) + 1
+}
+
+func (p *jsonParser) syntaxError(err error) error {
+	var se *json.SyntaxError
+	switch {
+	case errors.As(err, &se):
+		line, _ := p.position(int(se.Offset))
+		return &SyntaxError{Line: line, Msg: se.Error()}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		line, _ := p.position(len(p.src))
+		return &SyntaxError{Line: line, Msg: "unexpected end of JSON input"}
+	}
+	return &SyntaxError{Msg: err.Error()}
+}
@@ -0,0 +1,62 @@
+package podvalidate
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const jsonPod = `{
+  "apiVersion": "v1",
+  "kind": "Pod",
+  "metadata": {"name": "my-pod"},
+  "spec": {
+    "containers": [{
+      "name": "web",
+      "image": "registry.bigbrother.io/app:1.0",
+      "env": [{"name": "MSG", "value": %s}],
+      "ports": [{"containerPort": %s}],
+      "resources": {"limits": {"cpu": "500m", "memory": "128Mi"}}
+    }]
+  }
+}
+`
+
+func TestJSON(t *testing.T) {
+	pod := func(value, port string) string {
+		return strings.Replace(strings.Replace(jsonPod, "%s", value, 1), "%s", port, 1)
+	}
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"valid", pod(`"ok"`, "8080"), nil},
+		{"surrogate pair", pod(`"ship it \ud83d\ude80"`, "8080"), nil},
+		{"huge number", pod(`"ok"`, "1e400"), []string{"10 containerPort must be int"}},
+		{"float port", pod(`"ok"`, "80.5"), []string{"10 containerPort must be int"}},
+		{"number as string", pod("1e400", "8080"), []string{"9 value must be string"}},
+		{"root array", "[1, 2]", []string{"1 root must be object, got sequence"}},
+		{"empty", "", []string{"line 1: syntax error: unexpected end of JSON input"}},
+		{"truncated", `{"kind": "Pod",`, []string{"line 1: syntax error: unexpected end of JSON input"}},
+		{"bad token", "{\n\"kind\": Pod}", []string{"line 2: syntax error: invalid character 'P' looking for beginning of value"}},
+		{"trailing data", "{}\n{}", []string{"line 2: syntax error: invalid character after top-level value"}},
+		{"yaml", "kind: Pod\n", []string{"line 1: syntax error: invalid character 'k' looking for beginning of value"}},
+		{"too deep", strings.Repeat("[", maxJSONDepth+1), []string{"line 1: syntax error: exceeded max depth"}},
+	}
+	opts := DefaultOptions()
+	opts.JSON = true
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateWithOptions([]byte(tt.src), opts)
+			checkErrors(t, errs)
+			var got []string
+			for _, err := range errs {
+				got = append(got, err.Error())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1011 @@
+package podvalidate
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*************** Document ****************/
+type kindValidator struct {
+	apiVersion string
+	fields     []string
+	validate   func(v *validator, doc *yaml.Node, m map[string]*yaml.Node)
+}
+
+// kinds maps every supported kind to the apiVersion it belongs to, its
+// top-level fields and its validator; a new kind only needs an entry here.
+var kinds = map[string]kindValidator{
+	"Pod":        {"v1", []string{"apiVersion", "kind", "metadata", "spec"}, (*validator).validatePod},
+	"Deployment": {"apps/v1", []string{"apiVersion", "kind", "metadata", "spec"}, (*validator).validateDeployment},
+}
+
+// List validates its items through kinds, so it is registered in init to
+// break the initialization cycle.
+func init() {
+	kinds["List"] = kindValidator{"v1", []string{"apiVersion", "kind", "metadata", "items"}, (*validator).validateList}
+}
+
+func (v *validator) validateDocument(root *yaml.Node) {
+	var doc *yaml.Node
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		doc = root.Content[0]
+	} else {
+		doc = root
+	}
+
+	if doc.Kind != yaml.MappingNode {
+		v.fail(doc, CodeType, "", "root must be object, got %s", kindName(doc))
+		return
+	}
+	v.validateObject(doc)
+}
+
+func (v *validator) validateObject(doc *yaml.Node) {
+	if v.filtered(doc) {
+		return
+	}
+	m := v.mapify(doc)
+
+	// apiVersion
+	api, ok := m["apiVersion"]
+	if !ok {
+		v.required(doc, "apiVersion")
+		api = nil
+	} else if !isString(api) {
+		v.mustBe(api, "apiVersion", "string")
+		api = nil
+	} else if !slices.Contains(v.opts.APIVersions, api.Value) {
+		v.unsupported(api, "apiVersion")
+		api = nil
+	}
+
+	// kind; documents of a missing or unknown kind are still checked as a Pod
+	kind, known := kinds["Pod"], false
+	kd, ok := m["kind"]
+	if !ok {
+		v.required(doc, "kind")
+	} else if !isString(kd) {
+		v.mustBe(kd, "kind", "string")
+	} else if k, ok := kinds[kd.Value]; !ok {
+		v.unsupported(kd, "kind")
+	} else {
+		kind, known = k, true
+	}
+
+	v.checkUnknownFields(doc, kind.fields...)
+	kind.validate(v, doc, m)
+	if known && api != nil && api.Value != kind.apiVersion {
+		v.fail(kd, CodeConflict, "kind", "kind '%s' is not valid for apiVersion '%s'", kd.Value, api.Value)
+	}
+}
+
+// filtered reports whether doc is left out by Options.Kinds. A document
+// without a string kind is never left out, so its kind is still reported,
+// and neither is a List, whose items are filtered one by one.
+func (v *validator) filtered(doc *yaml.Node) bool {
+	if len(v.opts.Kinds) == 0 {
+		return false
+	}
+	kd, ok := nodeAtPath(doc, []string{"kind"})
+	if !ok || !isString(kd) || kd.Value == "List" || slices.Contains(v.opts.Kinds, kd.Value) {
+		return false
+	}
+	if v.opts.Log != nil {
+		v.opts.Log.Printf("skipping kind %s", kd.Value)
+	}
+	return true
+}
+
+/*************** List ****************/
+// validateList checks every item of a List, as printed by kubectl get -o
+// yaml, as an object of its own. Errors inside an item name its index.
+func (v *validator) validateList(doc *yaml.Node, m map[string]*yaml.Node) {
+	if meta, ok := m["metadata"]; ok && meta.Kind != yaml.MappingNode {
+		v.mustBe(meta, "metadata", "object")
+	}
+
+	items, ok := m["items"]
+	if !ok {
+		v.required(doc, "items")
+		return
+	}
+	if items.Kind != yaml.SequenceNode {
+		v.mustBe(items, "items", "array")
+		return
+	}
+
+	scope := v.scope
+	defer func() { v.scope = scope }()
+	for i, it := range items.Content {
+		v.scope = fmt.Sprintf("%sitems[%d]: ", scope, i)
+		if it.Kind != yaml.MappingNode {
+			v.fail(it, CodeType, "items", "item must be object")
+			continue
+		}
+		done := v.enter(fmt.Sprintf("items[%d]", i))
+		v.validateObject(it)
+		done()
+	}
+}
+
+/*************** Pod ****************/
+func (v *validator) validatePod(doc *yaml.Node, m map[string]*yaml.Node) {
+	// metadata
+	meta, ok := m["metadata"]
+	if !ok {
+		v.required(doc, "metadata")
+	} else {
+		v.validateMetadata(meta, true)
+	}
+
+	// spec
+	spec, ok := m["spec"]
+	if !ok {
+		v.required(doc, "spec")
+	} else {
+		v.validateSpec(spec)
+	}
+}
+
+/*************** Metadata ****************/
+// validateMetadata checks object metadata. Pod templates may omit the name.
+func (v *validator) validateMetadata(node *yaml.Node, named bool) {
+	defer v.enter("metadata")()
+	if node.Kind != yaml.MappingNode {
+		v.mustBe(node, "metadata", "object")
+		return
+	}
+	m := v.mapify(node)
+	v.checkUnknownFields(node, "name", "generateName", "namespace", "labels", "annotations")
+
+	// generateName; the server appends a random suffix when name is unset
+	gn, hasGen := m["generateName"]
+	if hasGen {
+		if !isString(gn) {
+			v.mustBe(gn, "generateName", "string")
+		} else if !isGenerateName(gn.Value) {
+			v.invalidFormat(gn, "generateName")
+		}
+	}
+
+	// name
+	nm, ok := m["name"]
+	if !ok {
+		if named && !hasGen {
+			v.required(node, "name")
+		}
+	} else if !isString(nm) {
+		v.mustBe(nm, "name", "string")
+	} else if strings.TrimSpace(nm.Value) == "" {
+		v.required(nm, "name")
+	} else if !isDNS1123Label(nm.Value) {
+		v.invalidFormat(nm, "name")
+	}
+
+	// namespace
+	if ns, ok := m["namespace"]; ok {
+		if !isString(ns) {
+			v.mustBe(ns, "namespace", "string")
+		} else if !isDNS1123Label(ns.Value) {
+			v.invalidFormat(ns, "namespace")
+		}
+	}
+
+	// labels
+	if lbs, ok := m["labels"]; ok {
+		if lbs.Kind != yaml.MappingNode {
+			v.mustBe(lbs, "labels", "object")
+		} else {
+			v.assertNoDuplicateKeys(lbs)
+			for i := 0; i+1 < len(lbs.Content); i += 2 {
+				k := lbs.Content[i]
+				val := lbs.Content[i+1]
+
+				if k.Tag != "!!str" {
+					v.fail(k, CodeType, "labels", "labels key must be string")
+				} else if !isLabelKey(k.Value) {
+					v.fail(k, CodeFormat, "labels", "labels key has invalid format '%s'", k.Value)
+				}
+				if val.Tag != "!!str" {
+					v.fail(val, CodeType, "labels", "labels value must be string")
+				} else if !isLabelValue(val.Value) {
+					v.fail(k, CodeFormat, "labels", "labels value has invalid format '%s'", val.Value)
+				}
+			}
+		}
+	}
+
+	// annotations
+	if ans, ok := m["annotations"]; ok {
+		if ans.Kind != yaml.MappingNode {
+			v.mustBe(ans, "annotations", "object")
+		} else {
+			v.assertNoDuplicateKeys(ans)
+			for i := 0; i+1 < len(ans.Content); i += 2 {
+				k := ans.Content[i]
+				val := ans.Content[i+1]
+
+				if k.Tag != "!!str" {
+					v.fail(k, CodeType, "annotations", "annotations key must be string")
+				} else if !isLabelKey(k.Value) {
+					v.fail(k, CodeFormat, "annotations", "annotations key has invalid format '%s'", k.Value)
+				}
+				if val.Tag != "!!str" {
+					v.fail(val, CodeType, "annotations", "annotations value must be string")
+				}
+			}
+		}
+	}
+}
+
+/*************** Spec ****************/
+func (v *validator) validateSpec(node *yaml.Node) {
+	defer v.enter("spec")()
+	if node.Kind != yaml.MappingNode {
+		v.mustBe(node, "spec", "object")
+		return
+	}
+	m := v.mapify(node)
+	v.checkUnknownFields(node, "os", "containers", "initContainers", "ephemeralContainers", "volumes", "restartPolicy", "dnsPolicy", "hostNetwork",
+		"terminationGracePeriodSeconds", "activeDeadlineSeconds", "securityContext", "nodeSelector",
+		"tolerations", "affinity")
+
+	// os optional: object as in PodSpec, or the legacy plain string
+	if osn, ok := m["os"]; ok {
+		switch osn.Kind {
+		case yaml.ScalarNode:
+			if !slices.Contains(v.opts.AllowedOS, osn.Value) {
+				v.unsupported(osn, "os")
+			} else {
+				v.warn(osn, CodeDeprecated, "os", "os as a plain string is deprecated, use os.name")
+			}
+		case yaml.MappingNode:
+			obj := v.mapify(osn)
+			n, ok := obj["name"]
+			if !ok {
+				v.required(osn, "name")
+			} else if !isString(n) {
+				v.mustBe(n, "name", "string")
+			} else if !slices.Contains(v.opts.AllowedOS, n.Value) {
+				v.unsupported(n, "name")
+			}
+		default:
+			v.mustBe(osn, "os", "string or object")
+		}
+	}
+
+	// restartPolicy
+	if rp, ok := m["restartPolicy"]; ok {
+		v.validateEnum(rp, "restartPolicy", validRestart)
+	}
+
+	// dnsPolicy
+	if dp, ok := m["dnsPolicy"]; ok {
+		v.validateEnum(dp, "dnsPolicy", validDNS)
+	}
+
+	// hostNetwork
+	if hn, ok := m["hostNetwork"]; ok && !isBool(hn) {
+		v.mustBe(hn, "hostNetwork", "bool")
+	}
+
+	// terminationGracePeriodSeconds, activeDeadlineSeconds
+	for _, f := range []string{"terminationGracePeriodSeconds", "activeDeadlineSeconds"} {
+		if n, ok := m[f]; ok {
+			v.validateIntMin(n, f, 0)
+		}
+	}
+
+	// nodeSelector
+	if ns, ok := m["nodeSelector"]; ok {
+		v.validateStringMap(ns, "nodeSelector")
+	}
+
+	// tolerations
+	if tl, ok := m["tolerations"]; ok {
+		v.validateTolerations(tl)
+	}
+
+	// affinity
+	if af, ok := m["affinity"]; ok && af.Kind != yaml.MappingNode {
+		v.mustBe(af, "affinity", "object")
+	}
+
+	// securityContext
+	if sc, ok := m["securityContext"]; ok {
+		v.validateSecurityContext(sc, podSecurityInts, podSecurityBools)
+	}
+
+	// volumes
+	v.volumes = make(map[string]bool)
+	if vols, ok := m["volumes"]; ok {
+		v.validateVolumes(vols)
+	}
+
+	// ephemeralContainers; only name and image, as they disallow many
+	// container fields
+	if ec, ok := m["ephemeralContainers"]; ok {
+		v.validateEphemeralContainers(ec)
+	}
+
+	// initContainers, containers required
+	if ic, ok := m["initContainers"]; ok {
+		v.validateContainers(ic, "initContainers")
+	}
+	if cn, ok := m["containers"]; !ok {
+		v.required(node, "containers")
+	} else {
+		v.validateContainers(cn, "containers")
+	}
+
+	v.checkContainerNames(m)
+}
+
+var containerLists = []string{"initContainers", "containers", "ephemeralContainers"}
+
+// checkContainerNames reports a container name used twice anywhere in the
+// Pod, as init, regular and ephemeral containers share one namespace.
+func (v *validator) checkContainerNames(spec map[string]*yaml.Node) {
+	first := make(map[string]string)
+	for _, list := range containerLists {
+		node, ok := spec[list]
+		if !ok || node.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range node.Content {
+			nm, ok := nodeAtPath(item, []string{"name"})
+			if !ok || !isString(nm) {
+				continue
+			}
+			if where, dup := first[nm.Value]; dup {
+				v.fail(nm, CodeDuplicate, "name", "duplicate container name '%s' in %s, first defined in %s", nm.Value, list, where)
+				continue
+			}
+			first[nm.Value] = list
+		}
+	}
+}
+
+func (v *validator) validateEphemeralContainers(node *yaml.Node) {
+	defer v.enter("ephemeralContainers")()
+	if node.Kind != yaml.SequenceNode {
+		v.mustBe(node, "ephemeralContainers", "array")
+		return
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			v.fail(item, CodeType, "ephemeralContainers", "ephemeralContainers item must be object")
+			continue
+		}
+		m := v.mapify(item)
+
+		nm, ok := m["name"]
+		if !ok {
+			v.required(item, "name")
+		} else if !isString(nm) {
+			v.mustBe(nm, "name", "string")
+		} else if !isDNS1123Label(nm.Value) {
+			v.invalidFormat(nm, "name")
+		}
+
+		img, ok := m["image"]
+		if !ok {
+			v.required(item, "image")
+		} else {
+			v.validateImage(img)
+		}
+	}
+}
+
+func (v *validator) validateContainers(node *yaml.Node, field string) {
+	if node.Kind != yaml.SequenceNode {
+		v.mustBe(node, field, "array")
+		return
+	}
+	for i, item := range node.Content {
+		done := v.enter(fmt.Sprintf("%s[%d]", field, i))
+		v.validateContainer(item)
+		done()
+	}
+}
+
+/*************** Container ****************/
+// validateContainer walks the container's keys once, so fields are checked
+// in source order and strict mode sees every key on the way.
+func (v *validator) validateContainer(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		v.mustBe(node, "container", "object")
+		return
+	}
+	v.assertNoDuplicateKeys(node)
+
+	tag := ""
+	var hasName, hasImage, hasResources bool
+	var pullPolicy *yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, val := node.Content[i], node.Content[i+1]
+		if k.Kind != yaml.ScalarNode {
+			continue
+		}
+		switch f := k.Value; f {
+		case "name":
+			hasName = true
+			if !isString(val) {
+				v.mustBe(val, "name", "string")
+			} else if !reSnake.MatchString(val.Value) {
+				v.invalidFormat(val, "name")
+			}
+		case "image":
+			hasImage = true
+			tag = v.validateImage(val)
+		case "imagePullPolicy":
+			// Checked once the image tag is known.
+			pullPolicy = val
+		case "ports":
+			v.validateContainerPorts(val)
+		case "readinessProbe", "livenessProbe":
+			v.validateProbe(f, val)
+		case "command", "args":
+			v.validateStringArray(val, f)
+		case "workingDir":
+			if !isString(val) {
+				v.mustBe(val, "workingDir", "string")
+			} else if !isAbsolutePath(val.Value) {
+				v.invalidFormat(val, "workingDir")
+			}
+		case "stdin", "stdinOnce", "tty":
+			if !isBool(val) {
+				v.mustBe(val, f, "bool")
+			}
+		case "securityContext":
+			v.validateSecurityContext(val, containerSecurityInts, containerSecurityBools)
+		case "env":
+			v.validateEnv(val)
+		case "volumeMounts":
+			v.validateVolumeMounts(val)
+		case "resources":
+			hasResources = true
+			v.validateResources(val)
+		default:
+			if v.opts.Strict {
+				v.unknownField(k)
+			}
+		}
+	}
+
+	if !hasName {
+		v.required(node, "name")
+	}
+	if !hasImage {
+		v.required(node, "image")
+	}
+	if !hasResources && v.opts.RequireResources {
+		v.required(node, "resources")
+	}
+	if pp := pullPolicy; pp != nil {
+		v.validateEnum(pp, "imagePullPolicy", validPull)
+		if tag == "latest" && validPull[pp.Value] && pp.Value != "Always" {
+			v.warn(pp, CodeLatestPullPolicy, "imagePullPolicy", "imagePullPolicy should be Always for image tag 'latest'")
+		}
+	}
+}
+
+func (v *validator) validateContainerPorts(node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		v.mustBe(node, "ports", "array")
+		return
+	}
+	seen, seenPorts := make(map[string]bool), make(map[string]bool)
+	for i, el := range node.Content {
+		done := v.enter(fmt.Sprintf("ports[%d]", i))
+		pn := v.validatePort(el, seenPorts)
+		done()
+		if pn == nil {
+			continue
+		}
+		if seen[pn.Value] {
+			v.fail(pn, CodeDuplicate, "name", "duplicate port name '%s'", pn.Value)
+		}
+		seen[pn.Value] = true
+	}
+}
+
+/*************** Tolerations ****************/
+func (v *validator) validateTolerations(node *yaml.Node) {
+	defer v.enter("tolerations")()
+	if node.Kind != yaml.SequenceNode {
+		v.mustBe(node, "tolerations", "array")
+		return
+	}
+	for _, t := range node.Content {
+		if t.Kind != yaml.MappingNode {
+			v.fail(t, CodeType, "tolerations", "tolerations item must be object")
+			continue
+		}
+		m := v.mapify(t)
+
+		if k, ok := m["key"]; ok && !isString(k) {
+			v.mustBe(k, "key", "string")
+		}
+		if op, ok := m["operator"]; ok {
+			v.validateEnum(op, "operator", validTolOp)
+		}
+		if ef, ok := m["effect"]; ok {
+			v.validateEnum(ef, "effect", validTolEffect)
+		}
+	}
+}
+
+/*************** SecurityContext ****************/
+var (
+	podSecurityInts        = []string{"runAsUser", "runAsGroup", "fsGroup"}
+	podSecurityBools       = []string{"runAsNonRoot"}
+	containerSecurityInts  = []string{"runAsUser", "runAsGroup"}
+	containerSecurityBools = []string{"runAsNonRoot", "privileged", "readOnlyRootFilesystem", "allowPrivilegeEscalation"}
+)
+
+func (v *validator) validateSecurityContext(node *yaml.Node, ints, bools []string) {
+	defer v.enter("securityContext")()
+	if node.Kind != yaml.MappingNode {
+		v.mustBe(node, "securityContext", "object")
+		return
+	}
+	m := v.mapify(node)
+
+	for _, f := range ints {
+		if n, ok := m[f]; ok {
+			v.validateIntMin(n, f, 0)
+		}
+	}
+	for _, f := range bools {
+		if n, ok := m[f]; ok && !isBool(n) {
+			v.mustBe(n, f, "bool")
+		}
+	}
+}
+
+/*************** Env ****************/
+func (v *validator) validateEnv(node *yaml.Node) {
+	defer v.enter("env")()
+	if node.Kind != yaml.SequenceNode {
+		v.mustBe(node, "env", "array")
+		return
+	}
+	for _, ev := range node.Content {
+		if ev.Kind != yaml.MappingNode {
+			v.fail(ev, CodeType, "env", "env item must be object")
+			continue
+		}
+		m := v.mapify(ev)
+
+		nm, ok := m["name"]
+		if !ok {
+			v.required(ev, "name")
+		} else if !isString(nm) {
+			v.mustBe(nm, "name", "string")
+		} else if !reCIdent.MatchString(nm.Value) {
+			v.invalidFormat(nm, "name")
+		}
+
+		if val, ok := m["value"]; ok && !isString(val) {
+			v.mustBe(val, "value", "string")
+		}
+		if vf, ok := m["valueFrom"]; ok && vf.Kind != yaml.MappingNode {
+			v.mustBe(vf, "valueFrom", "object")
+		}
+	}
+}
+
+/*************** Volumes ****************/
+func (v *validator) validateVolumes(node *yaml.Node) {
+	defer v.enter("volumes")()
+	if node.Kind != yaml.SequenceNode {
+		v.mustBe(node, "volumes", "array")
+		return
+	}
+	for _, vol := range node.Content {
+		if vol.Kind != yaml.MappingNode {
+			v.fail(vol, CodeType, "volumes", "volumes item must be object")
+			continue
+		}
+		m := v.mapify(vol)
+
+		nm, ok := m["name"]
+		if !ok {
+			v.required(vol, "name")
+			continue
+		}
+		if !isString(nm) {
+			v.mustBe(nm, "name", "string")
+			continue
+		}
+		if !isDNS1123Label(nm.Value) {
+			v.invalidFormat(nm, "name")
+		}
+		if v.volumes[nm.Value] {
+			v.fail(nm, CodeDuplicate, "name", "duplicate volume name '%s'", nm.Value)
+		}
+		v.volumes[nm.Value] = true
+	}
+}
+
+/*************** VolumeMounts ****************/
+func (v *validator) validateVolumeMounts(node *yaml.Node) {
+	defer v.enter("volumeMounts")()
+	if node.Kind != yaml.SequenceNode {
+		v.mustBe(node, "volumeMounts", "array")
+		return
+	}
+	seen := make(map[string]bool)
+	for _, mnt := range node.Content {
+		if mnt.Kind != yaml.MappingNode {
+			v.fail(mnt, CodeType, "volumeMounts", "volumeMounts item must be object")
+			continue
+		}
+		m := v.mapify(mnt)
+
+		nm, ok := m["name"]
+		if !ok {
+			v.required(mnt, "name")
+		} else if !isString(nm) {
+			v.mustBe(nm, "name", "string")
+		} else if !v.volumes[nm.Value] {
+			v.fail(nm, CodeUnknownReference, "name", "volumeMount references unknown volume '%s'", nm.Value)
+		}
+
+		mp, ok := m["mountPath"]
+		if !ok {
+			v.required(mnt, "mountPath")
+		} else if !isString(mp) {
+			v.mustBe(mp, "mountPath", "string")
+		} else if !isAbsolutePath(mp.Value) {
+			v.invalidFormat(mp, "mountPath")
+		} else {
+			if seen[mp.Value] {
+				v.fail(mp, CodeDuplicate, "mountPath", "duplicate mountPath '%s'", mp.Value)
+			}
+			seen[mp.Value] = true
+		}
+
+		if ro, ok := m["readOnly"]; ok && !isBool(ro) {
+			v.mustBe(ro, "readOnly", "bool")
+		}
+	}
+}
+
+/*************** Image ****************/
+// validateImage returns the image tag, or "" when the image is invalid or
+// pinned by digest.
+func (v *validator) validateImage(node *yaml.Node) string {
+	if !isString(node) {
+		v.mustBe(node, "image", "string")
+		return ""
+	}
+
+	registry, repo, tag, digest, err := parseImageRef(node.Value)
+	if err != nil || tag == "" && digest == "" {
+		v.invalidFormat(node, "image")
+		return ""
+	}
+	if v.opts.Registry != "" && !registryMatches(registry, repo, v.opts.Registry) {
+		v.invalidFormat(node, "image")
+		return ""
+	}
+	if len(v.opts.AllowedRepos) > 0 && !slices.ContainsFunc(v.opts.AllowedRepos, func(p string) bool {
+		ok, _ := path.Match(p, repo)
+		return ok
+	}) {
+		v.fail(node, CodeUnsupported, "image", "image repository '%s' is not allowed", repo)
+	}
+	if digest != "" {
+		return ""
+	}
+	if v.opts.TagPattern != nil && !v.opts.TagPattern.MatchString(tag) {
+		v.fail(node, CodeFormat, "image", "image tag '%s' does not match required pattern", tag)
+	}
+	return tag
+}
+
+/*************** ContainerPort ****************/
+// validatePort returns the port name node, if valid. Seen collects the
+// containerPort/protocol pairs of the container to catch duplicates.
+func (v *validator) validatePort(node *yaml.Node, seen map[string]bool) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		v.fail(node, CodeType, "ports", "ports item must be object")
+		return nil
+	}
+	m := v.mapify(node)
+	v.checkUnknownFields(node, "name", "containerPort", "hostPort", "protocol")
+
+	nm, ok := m["name"]
+	if ok {
+		if !isString(nm) {
+			v.mustBe(nm, "name", "string")
+			nm = nil
+		} else if !isIANASvcName(nm.Value) {
+			v.invalidFormat(nm, "name")
+		}
+	}
+
+	port := 0
+	cp, ok := m["containerPort"]
+	if !ok {
+		v.required(node, "containerPort")
+	} else {
+		port = v.validatePortNumber(cp, "containerPort")
+	}
+
+	// Binding a host port below 1024 needs extra capabilities on the node;
+	// strict mode rejects it outright.
+	if hp, ok := m["hostPort"]; ok {
+		if x := v.validatePortNumber(hp, "hostPort"); x > 0 && x < 1024 {
+			sev := SeverityWarning
+			if v.opts.Strict {
+				sev = SeverityError
+			}
+			v.report(sev, CodePrivilegedPort, hp.Line, hp.Column, "hostPort", "hostPort %d is a privileged port", x)
+		}
+	}
+
+	proto := "TCP"
+	if pr, ok := m["protocol"]; ok {
+		v.validateEnum(pr, "protocol", validPro)
+		proto = pr.Value
+	}
+
+	if port > 0 && validPro[proto] {
+		key := fmt.Sprintf("%d/%s", port, proto)
+		if seen[key] {
+			v.fail(cp, CodeDuplicate, "containerPort", "duplicate containerPort %s", key)
+		}
+		seen[key] = true
+	}
+	return nm
+}
+
+/*************** Probe ****************/
+var probeHandlers = []string{"httpGet", "exec", "tcpSocket"}
+
+// probeTimings maps each probe timing field to its minimal allowed value.
+var probeTimings = []struct {
+	field string
+	min   int
+}{
+	{"initialDelaySeconds", 0},
+	{"periodSeconds", 1},
+	{"timeoutSeconds", 1},
+	{"successThreshold", 1},
+	{"failureThreshold", 1},
+}
+
+func (v *validator) validateProbe(name string, node *yaml.Node) {
+	defer v.enter(name)()
+	if node.Kind != yaml.MappingNode {
+		v.mustBe(node, name, "object")
+		return
+	}
+	m := v.mapify(node)
+
+	for _, t := range probeTimings {
+		if n, ok := m[t.field]; ok {
+			v.validateIntMin(n, t.field, t.min)
+		}
+	}
+
+	var handlers []string
+	for _, h := range probeHandlers {
+		if _, ok := m[h]; ok {
+			handlers = append(handlers, h)
+		}
+	}
+	switch len(handlers) {
+	case 0:
+		v.fail(node, CodeRequired, name, "%s must specify one of httpGet, exec or tcpSocket", name)
+		return
+	case 1:
+	default:
+		v.fail(node, CodeConflict, name, "%s must specify only one of httpGet, exec or tcpSocket", name)
+		return
+	}
+
+	switch h := handlers[0]; h {
+	case "httpGet":
+		v.validateHTTPGet(m[h])
+	case "exec":
+		v.validateExec(m[h])
+	case "tcpSocket":
+		v.validateTCPSocket(m[h])
+	}
+}
+
+func (v *validator) validateHTTPGet(hg *yaml.Node) {
+	if hg.Kind != yaml.MappingNode {
+		v.mustBe(hg, "httpGet", "object")
+		return
+	}
+	obj := v.mapify(hg)
+
+	p, ok := obj["path"]
+	if !ok {
+		v.required(hg, "path")
+	} else if !isString(p) {
+		v.mustBe(p, "path", "string")
+	} else if !isAbsolutePath(p.Value) {
+		v.invalidFormat(p, "path")
+	}
+
+	prt, ok := obj["port"]
+	if !ok {
+		v.required(hg, "port")
+	} else {
+		v.validatePortNumber(prt, "port")
+	}
+
+	if sc, ok := obj["scheme"]; ok {
+		v.validateEnum(sc, "scheme", validScheme)
+	}
+
+	if host, ok := obj["host"]; ok && !isString(host) {
+		v.mustBe(host, "host", "string")
+	}
+}
+
+func (v *validator) validateExec(ex *yaml.Node) {
+	if ex.Kind != yaml.MappingNode {
+		v.mustBe(ex, "exec", "object")
+		return
+	}
+	obj := v.mapify(ex)
+
+	cmd, ok := obj["command"]
+	if !ok {
+		v.required(ex, "command")
+		return
+	}
+	v.validateStringArray(cmd, "command")
+}
+
+func (v *validator) validateStringArray(node *yaml.Node, field string) {
+	if node.Kind != yaml.SequenceNode {
+		v.mustBe(node, field, "array")
+		return
+	}
+	for i, el := range node.Content {
+		if !isString(el) {
+			v.fail(el, CodeType, field, "%s[%d] must be string", field, i)
+		}
+	}
+}
+
+func (v *validator) validateTCPSocket(ts *yaml.Node) {
+	if ts.Kind != yaml.MappingNode {
+		v.mustBe(ts, "tcpSocket", "object")
+		return
+	}
+	obj := v.mapify(ts)
+
+	prt, ok := obj["port"]
+	if !ok {
+		v.required(ts, "port")
+		return
+	}
+	v.validatePortNumber(prt, "port")
+}
+
+func (v *validator) validateIntMin(node *yaml.Node, field string, min int) {
+	x, ok := v.intValue(node)
+	if !ok {
+		v.mustBe(node, field, "int")
+		return
+	}
+	if x < min {
+		v.outOfRange(node, field)
+	}
+}
+
+// validatePortNumber returns the port, or 0 when it is not valid.
+func (v *validator) validatePortNumber(node *yaml.Node, field string) int {
+	x, ok := v.intValue(node)
+	if !ok {
+		v.mustBe(node, field, "int")
+		return 0
+	}
+	if x <= 0 || x >= 65536 {
+		v.outOfRange(node, field)
+		return 0
+	}
+	return x
+}
+
+/*************** Resources ****************/
+func (v *validator) validateResources(node *yaml.Node) {
+	defer v.enter("resources")()
+	if node.Kind != yaml.MappingNode {
+		v.mustBe(node, "resources", "object")
+		return
+	}
+	m := v.mapify(node)
+	// Quantities placed straight under resources get an error of their own
+	// rather than an unknown field.
+	v.checkUnknownFields(node, "limits", "requests", "cpu", "memory", "ephemeral-storage")
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if k := node.Content[i]; standardResources[k.Value] {
+			v.fail(k, CodeMisplacedResource, k.Value, "%s must be under limits or requests", k.Value)
+		}
+	}
+
+	var limits, requests map[string]*yaml.Node
+	lim, hasLim := m["limits"]
+	req, hasReq := m["requests"]
+	// An empty block is usually a mistake; teams relying on a LimitRange can
+	// ignore EMPTY_RESOURCES.
+	if !hasLim && !hasReq {
+		v.fail(node, CodeEmptyResources, "resources", "resources must specify limits or requests")
+	}
+	if hasLim {
+		limits = v.validateResKV("limits", lim)
+	}
+	if hasReq {
+		requests = v.validateResKV("requests", req)
+		if !hasLim {
+			v.warn(node, CodeMissingLimits, "limits", "limits is not set while requests is")
+		}
+	}
+
+	for _, res := range []string{"cpu", "memory", "ephemeral-storage"} {
+		rq, ok1 := requests[res]
+		lm, ok2 := limits[res]
+		if !ok1 || !ok2 {
+			continue
+		}
+		rv, ok1 := parseQuantity(res, rq.Value)
+		lv, ok2 := parseQuantity(res, lm.Value)
+		if ok1 && ok2 && rv > lv {
+			v.fail(rq, CodeRange, res, "requests.%s exceeds limits.%s", res, res)
+		}
+	}
+}
+
+// validateResKV checks a limits or requests map and returns its entries.
+func (v *validator) validateResKV(name string, node *yaml.Node) map[string]*yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		v.mustBe(node, name, "object")
+		return nil
+	}
+	m := v.mapify(node)
+
+	if cpu, ok := m["cpu"]; ok {
+		if cpu.Kind != yaml.ScalarNode {
+			v.mustBe(cpu, "cpu", "int or string")
+		} else if !isValidCPU(cpu) {
+			v.invalidFormat(cpu, "cpu")
+		} else if isZeroQuantity(cpu) {
+			v.outOfRange(cpu, "cpu")
+		}
+	}
+	for _, f := range []string{"memory", "ephemeral-storage"} {
+		if q, ok := m[f]; ok {
+			if q.Kind != yaml.ScalarNode {
+				v.mustBe(q, f, "string")
+			} else if !isValidMemory(q) {
+				v.invalidFormat(q, f)
+			} else if isZeroQuantity(q) {
+				v.outOfRange(q, f)
+			}
+		}
+	}
+
+	if v.opts.Strict {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := node.Content[i]
+			if !standardResources[k.Value] && !isExtendedResource(k.Value) {
+				v.fail(k, CodeUnknownField, k.Value, "unknown resource '%s'", k.Value)
+			}
+		}
+	}
+	return m
+}
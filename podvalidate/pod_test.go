@@ -0,0 +1,94 @@
+package podvalidate
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const validPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: my-pod
+spec:
+  containers:
+    - name: web
+      image: registry.bigbrother.io/app:1.0
+      resources:
+        limits:
+          cpu: 500m
+          memory: 128Mi
+`
+
+// messages validates src and returns every reported error as "line message".
+func messages(t *testing.T, src string, opts Options) []string {
+	t.Helper()
+	var res []string
+	for _, err := range ValidateWithOptions([]byte(src), opts) {
+		res = append(res, err.Error())
+	}
+	return res
+}
+
+func TestValidPod(t *testing.T) {
+	if got := messages(t, validPod, DefaultOptions()); len(got) != 0 {
+		t.Errorf("valid pod reported %q", got)
+	}
+}
+
+func TestRootKind(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"scalar", `"hello"`, []string{"1 root must be object, got scalar"}},
+		{"number", "42\n", []string{"1 root must be object, got scalar"}},
+		{"sequence", "- a\n- b\n", []string{"1 root must be object, got sequence"}},
+		{"flow sequence", "[1, 2]\n", []string{"1 root must be object, got sequence"}},
+		{"empty", "", []string{"empty document"}},
+		{"mapping", "kind: Pod\n", []string{"1 apiVersion is required", "1 metadata is required", "1 spec is required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messages(t, tt.src, DefaultOptions()); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoolAndNullScalars(t *testing.T) {
+	for _, val := range []string{"true", "null", "~"} {
+		src := `apiVersion: v1
+kind: Pod
+metadata:
+  name: ` + val + `
+spec:
+  containers:
+    - name: web
+      image: registry.bigbrother.io/app:1.0
+      ports:
+        - containerPort: ` + val + `
+      readinessProbe:
+        httpGet: {path: /health, port: ` + val + `}
+      resources: {limits: {cpu: 1}}
+`
+		want := []string{"4 name must be string", "10 containerPort must be int", "12 port must be int"}
+		if got := messages(t, src, DefaultOptions()); !slices.Equal(got, want) {
+			t.Errorf("%s: got %q, want %q", val, got, want)
+		}
+	}
+}
+
+func TestMisplacedResource(t *testing.T) {
+	src := strings.Replace(validPod, "        limits:\n", "        memory: 1Gi\n        limits:\n", 1)
+	errs := ValidateWithOptions([]byte(src), DefaultOptions())
+	if len(errs) != 1 {
+		t.Fatalf("got %v, want one error", errs)
+	}
+	e := errs[0].(*ValidationError)
+	if e.Code != CodeMisplacedResource || e.Message != "memory must be under limits or requests" || *e.Line != 10 {
+		t.Errorf("got %s %d %q", e.Code, *e.Line, e.Message)
+	}
+}
@@ -0,0 +1,366 @@
+// Package podvalidate checks Kubernetes Pod manifests against a subset of the
+// PodSpec schema and reports every problem found together with its source line.
+package podvalidate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity tells whether a ValidationError makes the manifest invalid.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Code is a stable machine-readable identifier of a class of problems.
+type Code string
+
+const (
+	CodeRequired          Code = "REQUIRED_FIELD_MISSING"
+	CodeType              Code = "TYPE_MISMATCH"
+	CodeUnsupported       Code = "UNSUPPORTED_VALUE"
+	CodeFormat            Code = "INVALID_FORMAT"
+	CodeRange             Code = "OUT_OF_RANGE"
+	CodeDuplicate         Code = "DUPLICATE_VALUE"
+	CodeUnknownField      Code = "UNKNOWN_FIELD"
+	CodeUnknownReference  Code = "UNKNOWN_REFERENCE"
+	CodeConflict          Code = "CONFLICTING_FIELDS"
+	CodeMissingLimits     Code = "MISSING_LIMITS"
+	CodeLatestPullPolicy  Code = "LATEST_PULL_POLICY"
+	CodeDeprecated        Code = "DEPRECATED"
+	CodePrivilegedPort    Code = "PRIVILEGED_PORT"
+	CodeEmptyResources    Code = "EMPTY_RESOURCES"
+	CodeEmptyDocument     Code = "EMPTY_DOCUMENT"
+	CodeMisplacedResource Code = "MISPLACED_RESOURCE"
+)
+
+// ValidationError describes a single problem found in a manifest.
+// Line and Column are nil when the position of the problem is unknown.
+// Doc is the 1-based document index in a multi-document stream, or 0 when
+// the stream holds a single document.
+type ValidationError struct {
+	Doc      int
+	Line     *int
+	Column   *int
+	Severity Severity
+	Code     Code
+	Field    string
+	Message  string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Line == nil {
+		return e.Message
+	}
+	return fmt.Sprintf("%d %s", *e.Line, e.Message)
+}
+
+// DefaultRegistry is the image registry required by DefaultOptions.
+const DefaultRegistry = "registry.bigbrother.io"
+
+// SyntaxError is returned when content cannot be parsed. Line is 0 when the
+// parser did not report a position.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return "syntax error: " + e.Msg
+	}
+	return fmt.Sprintf("line %d: syntax error: %s", e.Line, e.Msg)
+}
+
+var reYAMLError = regexp.MustCompile(`^yaml: (?:line (\d+): )?(.*)$`)
+
+// newSyntaxError extracts the line yaml.v3 embeds in its error text.
+func newSyntaxError(err error) error {
+	parts := reYAMLError.FindStringSubmatch(err.Error())
+	if parts == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(parts[1])
+	return &SyntaxError{Line: line, Msg: parts[2]}
+}
+
+// Options tunes the validation rules.
+type Options struct {
+	// FailFast stops reporting after the first validation error.
+	FailFast bool
+	// MaxErrors stops reporting after that many validation errors; 0 means
+	// no limit. Warnings are not counted.
+	MaxErrors int
+	// Strict reports keys that are not part of the known schema.
+	Strict bool
+	// Registry is the host every image must be pulled from, optionally
+	// followed by a path such as gcr.io/myproj the repository must start with.
+	// An empty value accepts images from any registry.
+	Registry string
+	// AllowedRepos, when not empty, lists path.Match patterns such as
+	// approved/* one of which must match the repository of every image, the
+	// part between the registry and the tag.
+	AllowedRepos []string
+	// TagPattern, when set, must match the tag of every image. Images pinned
+	// by digest have no tag and are not checked.
+	TagPattern *regexp.Regexp
+	// RequireResources makes resources mandatory on every container. When
+	// off, resources is still checked where present.
+	RequireResources bool
+	// APIVersions lists the accepted apiVersion values.
+	APIVersions []string
+	// IgnoreCodes lists the codes that are never reported.
+	IgnoreCodes []Code
+	// Severities overrides the severity of the listed codes. A code turned
+	// into a warning no longer counts for FailFast or MaxErrors.
+	Severities map[Code]Severity
+	// AllowedOS lists the accepted spec.os values.
+	AllowedOS []string
+	// JSON requires content to be a strict JSON document. Positions are still
+	// reported, but for minified JSON every error lands on the same line and
+	// only the column tells them apart.
+	JSON bool
+	// AllowEmpty downgrades the error for an empty stream to a warning. A
+	// stream of whitespace or comments only is never reported.
+	AllowEmpty bool
+	// Coerce accepts a quoted integer such as "8080" where an int is
+	// expected. The string must still parse as a base-10 integer. Off by
+	// default, so a quoted number is reported as a type mismatch.
+	Coerce bool
+	// Kinds, when not empty, limits validation to documents of these kinds;
+	// documents of other kinds pass unchecked.
+	Kinds []string
+	// Log, when set, receives a line for every part of a manifest the rules
+	// visit, such as "validating spec.containers[0].resources".
+	Log *log.Logger
+	// Schema replaces the built-in Pod rules with a JSON Schema when set.
+	Schema *Schema
+}
+
+// DefaultOptions returns the options used by Validate.
+func DefaultOptions() Options {
+	return Options{
+		Registry:         DefaultRegistry,
+		RequireResources: true,
+		APIVersions:      []string{"v1", "apps/v1"},
+		AllowedOS:        []string{"linux", "windows"},
+	}
+}
+
+// Validate checks content with the default options.
+func Validate(content []byte) []error {
+	return ValidateWithOptions(content, DefaultOptions())
+}
+
+// ValidateWithOptions checks every document in content. A YAML syntax error is
+// returned as a *SyntaxError; all other entries are *ValidationError, warnings included.
+// Content is untrusted input, so a panic in the parser or in a rule is
+// returned as an error rather than crashing the caller.
+func ValidateWithOptions(content []byte, opts Options) (errs []error) {
+	return validateStream(bytes.NewReader(content), opts)
+}
+
+// ValidateReader is ValidateWithOptions for a stream: documents are decoded
+// and checked one at a time, so memory stays bounded by the largest document
+// rather than the whole stream. JSON input holds a single document and is
+// read in full.
+func ValidateReader(r io.Reader, opts Options) []error {
+	return validateStream(r, opts)
+}
+
+func validateStream(r io.Reader, opts Options) (errs []error) {
+	defer func() {
+		if r := recover(); r != nil {
+			errs = []error{fmt.Errorf("internal error: %v", r)}
+		}
+	}()
+
+	v := &validator{opts: opts}
+	validate := v.validateDocument
+	if opts.Schema != nil {
+		validate = v.validateWithSchema
+	}
+
+	// Documents are numbered as they come; a lone document gets no number
+	// once the stream turns out to hold just one.
+	decode := decodeDocuments
+	if opts.JSON {
+		decode = decodeJSON
+	}
+	n, read, err := decode(r, func(doc int, root *yaml.Node) {
+		v.doc = doc
+		if opts.Log != nil {
+			opts.Log.Printf("validating document %d", doc)
+		}
+		validate(root)
+	})
+	if err != nil {
+		return []error{err}
+	}
+	// Whitespace, comments and bare separators are a placeholder, not a
+	// mistake; only a stream without a single byte is reported.
+	if n == 0 && !read {
+		sev := SeverityError
+		if opts.AllowEmpty {
+			sev = SeverityWarning
+		}
+		v.report(sev, CodeEmptyDocument, 0, 0, "", "empty document")
+	}
+
+	errs = make([]error, 0, len(v.errs))
+	for _, e := range v.errs {
+		if n == 1 {
+			e.Doc = 0
+		}
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+// decodeDocuments calls fn for every document in r that is not empty, with
+// aliases resolved, numbering them from 1. It returns the number of documents
+// and whether r held any byte at all. A read error is returned as is, a
+// parse error as a *SyntaxError.
+func decodeDocuments(r io.Reader, fn func(doc int, root *yaml.Node)) (n int, read bool, err error) {
+	rr := &streamReader{r: r}
+	dec := yaml.NewDecoder(rr)
+	for {
+		var root yaml.Node
+		if err := dec.Decode(&root); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if rr.err != nil {
+				return n, rr.read, rr.err
+			}
+			// yaml.v3 rejects a tab even where the stream holds nothing else.
+			if n == 0 && rr.blank() {
+				break
+			}
+			return n, rr.read, newSyntaxError(err)
+		}
+		if isEmptyDocument(&root) {
+			continue
+		}
+		if err := resolveAliases(&root); err != nil {
+			return n, rr.read, err
+		}
+		n++
+		fn(n, &root)
+	}
+	return n, rr.read, nil
+}
+
+// streamReader keeps the read error that yaml.v3 would otherwise turn into
+// a syntax error, and notes whether the stream held any byte at all and any
+// line other than whitespace, a comment or a document separator.
+type streamReader struct {
+	r    io.Reader
+	err  error
+	read bool
+	text bool
+
+	// State of the current line while text is false: lead holds its first
+	// '-' or '.' characters, gap is set once a separator is followed by
+	// whitespace, and skip once the rest of the line is a comment.
+	lead []byte
+	gap  bool
+	skip bool
+}
+
+func (rr *streamReader) Read(p []byte) (int, error) {
+	n, err := rr.r.Read(p)
+	if n > 0 {
+		rr.read = true
+	}
+	for _, c := range p[:n] {
+		if rr.text {
+			break
+		}
+		rr.scan(c)
+	}
+	if err != nil && err != io.EOF {
+		rr.err = err
+	}
+	return n, err
+}
+
+func (rr *streamReader) scan(c byte) {
+	sep := len(rr.lead) == 3
+	switch {
+	case c == '\n':
+		rr.endLine()
+	case rr.skip:
+	case c == ' ' || c == '\t' || c == '\r':
+		if sep {
+			rr.gap = true
+		} else if len(rr.lead) > 0 {
+			rr.text = true
+		}
+	case c == '#' && (len(rr.lead) == 0 || sep && rr.gap):
+		rr.skip = true
+	case !sep && (c == '-' || c == '.') && (len(rr.lead) == 0 || rr.lead[0] == c):
+		rr.lead = append(rr.lead, c)
+	default:
+		rr.text = true
+	}
+}
+
+// endLine ends the current line; a lone "-" or ".." is text, not a separator.
+func (rr *streamReader) endLine() {
+	if len(rr.lead) > 0 && len(rr.lead) < 3 {
+		rr.text = true
+	}
+	rr.lead, rr.gap, rr.skip = rr.lead[:0], false, false
+}
+
+// blank reads the rest of the stream and reports whether it held nothing but
+// whitespace, comments and document separators.
+func (rr *streamReader) blank() bool {
+	if !rr.text {
+		io.Copy(io.Discard, rr)
+		rr.endLine()
+	}
+	return !rr.text && rr.err == nil
+}
+
+// LineAt returns the source line of the node at path in the first document
+// of content. Path is a JSON pointer such as /spec/containers/0/image or a
+// dotted path such as spec.containers[0].image, which lets callers map
+// errors from their own validators back to the manifest.
+func LineAt(content []byte, path string) (int, bool) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil || resolveAliases(&root) != nil {
+		return 0, false
+	}
+	n, ok := nodeAtPath(&root, splitPath(path))
+	if !ok {
+		return 0, false
+	}
+	return n.Line, true
+}
+
+func isEmptyDocument(root *yaml.Node) bool {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return true
+	}
+	n := root.Content[0]
+	return n.Kind == yaml.ScalarNode && n.Tag == "!!null" && n.Value == ""
+}
@@ -0,0 +1,31 @@
+package podvalidate
+
+import (
+	"testing"
+)
+
+func FuzzValidate(f *testing.F) {
+	for _, seed := range []string{
+		validPod,
+		validPod + "---\n" + validPod,
+		"",
+		"# placeholder\n",
+		"- a\n- b\n",
+		"apiVersion: v1\nkind: Pod\nmetadata: {name: a}\nspec: {containers: [{name: x, image: nginx, ports: [{containerPort: 0}]}]}\n",
+		"apiVersion: v1\nkind: Pod\nmetadata: {name: a, labels: &l {app: web}}\nspec: {nodeSelector: *l}\n",
+		"a: &a [*a]\n",
+		"apiVersion: v1\nkind: List\nitems:\n  - kind: Pod\n",
+		"apiVersion: apps/v1\nkind: Deployment\nspec: {template: {spec: {containers: 5}}}\n",
+		"spec:\n  containers:\n    - name: [\n",
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, content []byte) {
+		checkErrors(t, Validate(content))
+
+		opts := DefaultOptions()
+		opts.Strict = true
+		opts.JSON = true
+		checkErrors(t, ValidateWithOptions(content, opts))
+	})
+}
@@ -0,0 +1,127 @@
+package podvalidate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// checkErrors fails unless every entry of errs is one of the documented
+// error types. A recovered panic comes back as a plain error and fails too.
+func checkErrors(t *testing.T, errs []error) {
+	t.Helper()
+	for _, err := range errs {
+		switch err.(type) {
+		case *ValidationError, *SyntaxError:
+		default:
+			t.Errorf("unexpected error %T: %v", err, err)
+		}
+	}
+}
+
+func TestTruncatedInput(t *testing.T) {
+	src := validPod + `      ports:
+        - containerPort: 8080
+      readinessProbe:
+        httpGet: {path: /health, port: 8080}
+      env:
+        - name: MODE
+          value: "prod"
+  volumes:
+    - name: data
+      emptyDir: {}
+`
+	for i := range len(src) {
+		checkErrors(t, ValidateWithOptions([]byte(src[:i]), DefaultOptions()))
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("apiVersion: v1\nkind: Pod\nmetadata:\n  name: big\nspec:\n  containers:\n")
+	for i := range 200 {
+		fmt.Fprintf(&sb, `    - name: c%d
+      image: registry.bigbrother.io/app:1.0
+      imagePullPolicy: IfNotPresent
+      workingDir: /srv
+      command: [/bin/app]
+      args: [--port, "%d"]
+      ports:
+        - containerPort: %d
+      env:
+        - name: MODE
+          value: prod
+      readinessProbe:
+        httpGet: {path: /health, port: %d}
+      resources:
+        requests: {cpu: 100m, memory: 64Mi}
+        limits: {cpu: 500m, memory: 128Mi}
+`, i, 8000+i, 8000+i, 8000+i)
+	}
+	content := []byte(sb.String())
+	opts := DefaultOptions()
+	opts.Strict = true
+
+	b.ReportAllocs()
+	for range b.N {
+		if errs := ValidateWithOptions(content, opts); len(errs) != 0 {
+			b.Fatal(errs)
+		}
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		allowEmpty bool
+		want       []string
+		warning    bool
+	}{
+		{name: "empty", src: "", want: []string{"empty document"}},
+		{name: "empty allowed", src: "", allowEmpty: true, want: []string{"empty document"}, warning: true},
+		{name: "newlines", src: "\n\n"},
+		{name: "whitespace", src: "  \r\n\t\n "},
+		{name: "comments", src: "# placeholder\n  # indented\n"},
+		{name: "separators", src: "---\n# generated\n---\n"},
+		{name: "comment and tab", src: "# placeholder\n\t\n"},
+		{name: "separators and tab", src: "---\n\t\n---\n"},
+		{name: "tab before comment", src: "\t# placeholder\n... \t# end\n"},
+		{name: "document after comment", src: "# pod\n" + validPod},
+		{name: "missing apiVersion", src: "kind: Pod\nmetadata: {name: a}\nspec: {containers: []}\n", want: []string{"1 apiVersion is required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.AllowEmpty = tt.allowEmpty
+			errs := ValidateWithOptions([]byte(tt.src), opts)
+			checkErrors(t, errs)
+			var got []string
+			for _, err := range errs {
+				got = append(got, err.Error())
+				if e, ok := err.(*ValidationError); ok && (e.Severity == SeverityWarning) != tt.warning {
+					t.Errorf("%s: got severity %s", e.Message, e.Severity)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlankReader(t *testing.T) {
+	blank := strings.Repeat(" \t\n", 5000)
+	if errs := ValidateReader(iotest.OneByteReader(strings.NewReader(blank)), DefaultOptions()); len(errs) != 0 {
+		t.Errorf("blank stream reported %v", errs)
+	}
+	errs := ValidateReader(iotest.OneByteReader(strings.NewReader(blank+"kind: Pod\n")), DefaultOptions())
+	if len(errs) != 1 {
+		t.Fatalf("got %v, want a syntax error", errs)
+	}
+	if _, ok := errs[0].(*SyntaxError); !ok {
+		t.Errorf("got %T %v, want *SyntaxError", errs[0], errs[0])
+	}
+}
@@ -0,0 +1,77 @@
+package podvalidate
+
+import (
+	"math/big"
+	"regexp"
+)
+
+/*************** Quantity ****************/
+var reQuantity = regexp.MustCompile(`^([0-9]+(?:\.[0-9]*)?|\.[0-9]+)([A-Za-z]*)$`)
+
+// quantitySuffixes maps a Kubernetes quantity suffix to its multiplier.
+var quantitySuffixes = map[string]*big.Rat{
+	"":   big.NewRat(1, 1),
+	"m":  big.NewRat(1, 1000),
+	"k":  big.NewRat(1e3, 1),
+	"K":  big.NewRat(1e3, 1),
+	"M":  big.NewRat(1e6, 1),
+	"G":  big.NewRat(1e9, 1),
+	"T":  big.NewRat(1e12, 1),
+	"P":  big.NewRat(1e15, 1),
+	"E":  big.NewRat(1e18, 1),
+	"Ki": big.NewRat(1<<10, 1),
+	"Mi": big.NewRat(1<<20, 1),
+	"Gi": big.NewRat(1<<30, 1),
+	"Ti": big.NewRat(1<<40, 1),
+	"Pi": big.NewRat(1<<50, 1),
+	"Ei": big.NewRat(1<<60, 1),
+}
+
+// parseQuantity converts a Kubernetes quantity such as 250m, 0.5, 512Mi or
+// 1G into the unit of resource: millicores for cpu and bytes for anything
+// else, rounding fractions up. It reports false for malformed input and for
+// values that do not fit into int64.
+func parseQuantity(resource, s string) (int64, bool) {
+	num, ok := quantityValue(s)
+	if !ok {
+		return 0, false
+	}
+	if resource == "cpu" {
+		num.Mul(num, big.NewRat(1000, 1))
+	}
+
+	q, r := new(big.Int).QuoRem(num.Num(), num.Denom(), new(big.Int))
+	if r.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if !q.IsInt64() {
+		return 0, false
+	}
+	return q.Int64(), true
+}
+
+// quantityValue returns the exact value of a quantity in base units.
+func quantityValue(s string) (*big.Rat, bool) {
+	number, suffix, ok := splitQuantity(s)
+	if !ok {
+		return nil, false
+	}
+	mult, ok := quantitySuffixes[suffix]
+	if !ok {
+		return nil, false
+	}
+	num, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return nil, false
+	}
+	return num.Mul(num, mult), true
+}
+
+// splitQuantity separates the numeric part of a quantity from its suffix.
+func splitQuantity(s string) (number, suffix string, ok bool) {
+	parts := reQuantity.FindStringSubmatch(s)
+	if parts == nil {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
@@ -0,0 +1,44 @@
+package podvalidate
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		resource string
+		in       string
+		want     int64
+		ok       bool
+	}{
+		{"cpu", "1", 1000, true},
+		{"cpu", "0.5", 500, true},
+		{"cpu", ".5", 500, true},
+		{"cpu", "250m", 250, true},
+		{"cpu", "0.0001", 1, true},
+		{"cpu", "0", 0, true},
+		{"memory", "128", 128, true},
+		{"memory", "1k", 1000, true},
+		{"memory", "1K", 1000, true},
+		{"memory", "1M", 1000000, true},
+		{"memory", "1G", 1000000000, true},
+		{"memory", "1Ki", 1024, true},
+		{"memory", "1Mi", 1 << 20, true},
+		{"memory", "1.5Gi", 3 << 29, true},
+		{"memory", "1Ti", 1 << 40, true},
+		{"memory", "10Pi", 10 << 50, true},
+		{"memory", "1Ei", 1 << 60, true},
+		{"memory", "7E", 7e18, true},
+		{"memory", "8Ei", 0, false},
+		{"cpu", "10E", 0, false},
+		{"memory", "", 0, false},
+		{"memory", "10GiB", 0, false},
+		{"memory", "1.2.3", 0, false},
+		{"memory", "-1", 0, false},
+		{"memory", "1e3", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseQuantity(tt.resource, tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseQuantity(%q, %q) = %d, %v; want %d, %v", tt.resource, tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
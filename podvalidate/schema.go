@@ -0,0 +1,300 @@
+package podvalidate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*************** Schema ****************/
+// Schema is the subset of JSON Schema understood by the validator: type,
+// properties, required, additionalProperties, items, enum, pattern,
+// minimum/maximum, minLength/maxLength and minItems/maxItems.
+type Schema struct {
+	Type                 schemaTypes        `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *additionalProps   `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+
+	re *regexp.Regexp
+}
+
+// schemaTypes accepts both "type": "string" and "type": ["string", "null"].
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// additionalProps accepts both a boolean and a schema.
+type additionalProps struct {
+	allowed bool
+	schema  *Schema
+}
+
+func (a *additionalProps) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.allowed); err == nil {
+		return nil
+	}
+	a.allowed = true
+	return json.Unmarshal(data, &a.schema)
+}
+
+// ParseSchema decodes a JSON Schema document.
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schema) compile() error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", s.Pattern, err)
+		}
+		s.re = re
+	}
+	for _, p := range s.Properties {
+		if err := p.compile(); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.compile(); err != nil {
+			return err
+		}
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.schema != nil {
+		return s.AdditionalProperties.schema.compile()
+	}
+	return nil
+}
+
+func (v *validator) validateWithSchema(root *yaml.Node) {
+	doc := root
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		doc = root.Content[0]
+	}
+	if v.filtered(doc) {
+		return
+	}
+
+	var val any
+	if err := doc.Decode(&val); err != nil {
+		v.fail(doc, CodeType, "", "%v", err)
+		return
+	}
+	v.checkSchema(doc, v.opts.Schema, normalizeKeys(val), nil)
+}
+
+// checkSchema validates val against s and reports violations at the node
+// found under path in doc.
+func (v *validator) checkSchema(doc *yaml.Node, s *Schema, val any, path []string) {
+	at := func() *yaml.Node {
+		if n, ok := nodeAtPath(doc, path); ok {
+			return n
+		}
+		return doc
+	}
+	field := "root"
+	if len(path) > 0 {
+		field = path[len(path)-1]
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasJSONType(val, t) }) {
+		v.mustBe(at(), field, s.Type[0])
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return sameValue(e, val) }) {
+		v.fail(at(), CodeUnsupported, field, "%s has unsupported value '%v'", field, val)
+	}
+
+	switch x := val.(type) {
+	case string:
+		if (s.re != nil && !s.re.MatchString(x)) ||
+			(s.MinLength != nil && len(x) < *s.MinLength) ||
+			(s.MaxLength != nil && len(x) > *s.MaxLength) {
+			v.fail(at(), CodeFormat, field, "%s has invalid format '%s'", field, x)
+		}
+	case int, int64, uint64, float64:
+		f := toFloat(x)
+		if (s.Minimum != nil && f < *s.Minimum) || (s.Maximum != nil && f > *s.Maximum) {
+			v.outOfRange(at(), field)
+		}
+	case []any:
+		if (s.MinItems != nil && len(x) < *s.MinItems) || (s.MaxItems != nil && len(x) > *s.MaxItems) {
+			v.outOfRange(at(), field)
+		}
+		if s.Items != nil {
+			for i, el := range x {
+				v.checkSchema(doc, s.Items, el, append(slices.Clip(path), strconv.Itoa(i)))
+			}
+		}
+	case map[string]any:
+		for _, req := range s.Required {
+			if _, ok := x[req]; !ok {
+				v.required(at(), req)
+			}
+		}
+		for _, key := range sortedKeys(x) {
+			sub := append(slices.Clip(path), key)
+			if ps, ok := s.Properties[key]; ok {
+				v.checkSchema(doc, ps, x[key], sub)
+				continue
+			}
+			if ap := s.AdditionalProperties; ap != nil {
+				if !ap.allowed {
+					n, _ := resolveKey(doc, sub)
+					v.fail(n, CodeUnknownField, key, "unknown field '%s'", key)
+				} else if ap.schema != nil {
+					v.checkSchema(doc, ap.schema, x[key], sub)
+				}
+			}
+		}
+	}
+}
+
+func hasJSONType(val any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := val.(map[string]any)
+		return ok
+	case "array":
+		_, ok := val.([]any)
+		return ok
+	case "string":
+		_, ok := val.(string)
+		return ok
+	case "boolean":
+		_, ok := val.(bool)
+		return ok
+	case "null":
+		return val == nil
+	case "number":
+		switch val.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+	case "integer":
+		switch x := val.(type) {
+		case int, int64, uint64:
+			return true
+		case float64:
+			return x == math.Trunc(x)
+		}
+	}
+	return false
+}
+
+// toFloat converts the numbers yaml.v3 decodes: int, int64 when the value
+// overflows int, uint64 when it overflows int64, and float64.
+func toFloat(val any) float64 {
+	switch x := val.(type) {
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case uint64:
+		return float64(x)
+	case float64:
+		return x
+	}
+	return 0
+}
+
+// normalizeKeys turns the map[any]any yaml.v3 decodes for a mapping with
+// non-string keys, such as 1: a, into a map[string]any keyed by the key's
+// text, as Kubernetes does when it converts YAML to JSON.
+func normalizeKeys(val any) any {
+	switch x := val.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(x))
+		for k, el := range x {
+			key := "null"
+			if k != nil {
+				key = fmt.Sprint(k)
+			}
+			m[key] = normalizeKeys(el)
+		}
+		return m
+	case map[string]any:
+		for k, el := range x {
+			x[k] = normalizeKeys(el)
+		}
+	case []any:
+		for i, el := range x {
+			x[i] = normalizeKeys(el)
+		}
+	}
+	return val
+}
+
+// sameValue compares a JSON enum entry with a decoded YAML value.
+func sameValue(enum, val any) bool {
+	switch e := enum.(type) {
+	case float64:
+		switch val.(type) {
+		case int, int64, uint64, float64:
+			return e == toFloat(val)
+		}
+		return false
+	case string, bool, nil:
+		return e == val
+	}
+	return false
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// resolveKey returns the key node of the last path segment, falling back to
+// the document itself.
+func resolveKey(doc *yaml.Node, path []string) (*yaml.Node, bool) {
+	parent, ok := nodeAtPath(doc, path[:len(path)-1])
+	if !ok || parent.Kind != yaml.MappingNode {
+		return doc, false
+	}
+	key := path[len(path)-1]
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key {
+			return parent.Content[i], true
+		}
+	}
+	return doc, false
+}
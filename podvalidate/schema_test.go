@@ -0,0 +1,44 @@
+package podvalidate
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSchemaTypes(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"count": {"type": "integer", "minimum": 0},
+			"ratio": {"type": "number", "enum": [18446744073709551615, 0.5]},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"int", "count: 3\n", nil},
+		{"uint64", "count: 18446744073709551615\n", nil},
+		{"uint64 number", "ratio: 18446744073709551615\n", nil},
+		{"negative", "count: -1\n", []string{"1 count value out of range"}},
+		{"float", "count: 1.5\n", []string{"1 count must be integer"}},
+		{"string", "count: \"3\"\n", []string{"1 count must be integer"}},
+		{"int keys", "labels:\n  1: a\n  true: b\n", nil},
+		{"int key value", "labels:\n  1: 2\n", []string{"2 1 must be string"}},
+		{"int key root", "1: a\n", nil},
+	}
+	opts := DefaultOptions()
+	opts.Schema = schema
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messages(t, tt.src, opts); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
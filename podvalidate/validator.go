@@ -0,0 +1,409 @@
+package podvalidate
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*************** Validator ****************/
+type validator struct {
+	opts    Options
+	doc     int
+	nerrs   int
+	scope   string // prefixed to messages, e.g. "items[2]: "
+	path    []string
+	errs    []*ValidationError
+	volumes map[string]bool
+}
+
+func (v *validator) report(sev Severity, code Code, line, col int, field, msg string, args ...any) {
+	if slices.Contains(v.opts.IgnoreCodes, code) {
+		return
+	}
+	if s, ok := v.opts.Severities[code]; ok {
+		sev = s
+	}
+	if sev == SeverityError {
+		if v.opts.FailFast && v.nerrs > 0 {
+			return
+		}
+		if v.opts.MaxErrors > 0 && v.nerrs >= v.opts.MaxErrors {
+			return
+		}
+		v.nerrs++
+	}
+	e := &ValidationError{Doc: v.doc, Severity: sev, Code: code, Field: field, Message: v.scope + fmt.Sprintf(msg, args...)}
+	if line > 0 {
+		e.Line = &line
+	}
+	if col > 0 {
+		e.Column = &col
+	}
+	v.errs = append(v.errs, e)
+}
+
+// enter logs that validation of seg, below the current path, starts and
+// returns a func that leaves it again. It does nothing without a logger.
+func (v *validator) enter(seg string) func() {
+	if v.opts.Log == nil {
+		return func() {}
+	}
+	n := len(v.path)
+	v.path = append(v.path, seg)
+	v.opts.Log.Printf("validating %s", strings.Join(v.path, "."))
+	return func() { v.path = v.path[:n] }
+}
+
+func (v *validator) fail(node *yaml.Node, code Code, field, msg string, args ...any) {
+	v.report(SeverityError, code, node.Line, node.Column, field, msg, args...)
+}
+
+func (v *validator) warn(node *yaml.Node, code Code, field, msg string, args ...any) {
+	v.report(SeverityWarning, code, node.Line, node.Column, field, msg, args...)
+}
+
+// required reports a missing field at the line of its parent; the column is
+// left unset since the field itself is nowhere in the source.
+func (v *validator) required(parent *yaml.Node, field string) {
+	v.report(SeverityError, CodeRequired, parent.Line, 0, field, "%s is required", field)
+}
+
+func (v *validator) mustBe(node *yaml.Node, field, typ string) {
+	v.fail(node, CodeType, field, "%s must be %s", field, typ)
+}
+
+func (v *validator) unsupported(node *yaml.Node, field string) {
+	v.fail(node, CodeUnsupported, field, "%s has unsupported value '%s'", field, node.Value)
+}
+
+func (v *validator) invalidFormat(node *yaml.Node, field string) {
+	v.fail(node, CodeFormat, field, "%s has invalid format '%s'", field, node.Value)
+}
+
+func (v *validator) outOfRange(node *yaml.Node, field string) {
+	v.fail(node, CodeRange, field, "%s value out of range", field)
+}
+
+func (v *validator) validateEnum(node *yaml.Node, field string, allowed map[string]bool) {
+	if !isString(node) {
+		v.mustBe(node, field, "string")
+	} else if !allowed[node.Value] {
+		v.unsupported(node, field)
+	}
+}
+
+// validateStringMap checks a map of label-style keys to string values, such
+// as a selector.
+func (v *validator) validateStringMap(node *yaml.Node, field string) {
+	if node.Kind != yaml.MappingNode {
+		v.mustBe(node, field, "object")
+		return
+	}
+	v.assertNoDuplicateKeys(node)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k := node.Content[i]
+		if !isString(k) {
+			v.fail(k, CodeType, field, "%s key must be string", field)
+		} else if !isLabelKey(k.Value) {
+			v.fail(k, CodeFormat, field, "%s key has invalid format '%s'", field, k.Value)
+		}
+		if val := node.Content[i+1]; !isString(val) {
+			v.fail(val, CodeType, field, "%s value must be string", field)
+		}
+	}
+}
+
+func (v *validator) requiredField(node *yaml.Node, field string) (*yaml.Node, bool) {
+	m := v.mapify(node)
+	val, ok := m[field]
+	if !ok {
+		v.required(node, field)
+		return nil, false
+	}
+	return val, true
+}
+
+/*************** Helpers ****************/
+// nodeAtPath walks root following mapping keys and sequence indexes, e.g.
+// ["spec", "containers", "0", "image"]. A document node is entered
+// transparently. Repeated keys resolve to the last one, as in mapify.
+func nodeAtPath(root *yaml.Node, path []string) (*yaml.Node, bool) {
+	n := root
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	for _, seg := range path {
+		switch n.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == seg {
+					next = n.Content[i+1]
+				}
+			}
+			if next == nil {
+				return nil, false
+			}
+			n = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(n.Content) {
+				return nil, false
+			}
+			n = n.Content[i]
+		default:
+			return nil, false
+		}
+	}
+	return n, true
+}
+
+// splitPath turns a JSON pointer (/spec/containers/0/image) or a dotted path
+// (spec.containers[0].image) into path segments.
+func splitPath(path string) []string {
+	if strings.HasPrefix(path, "/") {
+		segs := strings.Split(path[1:], "/")
+		for i, seg := range segs {
+			segs[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(seg)
+		}
+		return segs
+	}
+
+	var segs []string
+	for _, part := range strings.Split(path, ".") {
+		for {
+			i := strings.IndexByte(part, '[')
+			if i < 0 || !strings.HasSuffix(part, "]") {
+				break
+			}
+			if i > 0 {
+				segs = append(segs, part[:i])
+			}
+			j := strings.IndexByte(part, ']')
+			segs = append(segs, part[i+1:j])
+			part = part[j+1:]
+		}
+		if part != "" {
+			segs = append(segs, part)
+		}
+	}
+	return segs
+}
+
+// mapify indexes the keys of a mapping in a single pass and reports duplicate
+// keys. The index is rebuilt on every call, so each rule calls it once for its
+// node and then looks fields up in the map. A repeated key resolves to its
+// last value, as when decoding into a map.
+func (v *validator) mapify(n *yaml.Node) map[string]*yaml.Node {
+	res := make(map[string]*yaml.Node)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return res
+	}
+	v.assertNoDuplicateKeys(n)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := n.Content[i]
+		if k.Kind == yaml.ScalarNode {
+			res[k.Value] = n.Content[i+1]
+		}
+	}
+	return res
+}
+
+// checkUnknownFields reports keys outside known when strict mode is on.
+func (v *validator) checkUnknownFields(n *yaml.Node, known ...string) {
+	if !v.opts.Strict {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := n.Content[i]
+		if k.Kind == yaml.ScalarNode && !slices.Contains(known, k.Value) {
+			v.unknownField(k)
+		}
+	}
+}
+
+func (v *validator) unknownField(k *yaml.Node) {
+	v.fail(k, CodeUnknownField, k.Value, "unknown field '%s'", k.Value)
+}
+
+// assertNoDuplicateKeys also reports a mapping whose content is not made of
+// key/value pairs; every pair walk stops before such a dangling key.
+func (v *validator) assertNoDuplicateKeys(n *yaml.Node) {
+	if len(n.Content)%2 != 0 {
+		v.fail(n, CodeFormat, "", "malformed mapping")
+	}
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := n.Content[i]
+		if k.Kind != yaml.ScalarNode {
+			continue
+		}
+		if seen[k.Value] {
+			v.fail(k, CodeDuplicate, k.Value, "duplicate key '%s'", k.Value)
+		}
+		seen[k.Value] = true
+	}
+}
+
+// isInt and isString go by the resolved tag, so null, ~ and true/false never
+// pass as either. yaml.v3 follows YAML 1.2, where yes/no/on/off are strings.
+func isInt(node *yaml.Node) bool {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+		return false
+	}
+	_, err := strconv.Atoi(node.Value)
+	return err == nil
+}
+
+// intValue returns the value of an int node, or of a numeric string when
+// coercion is enabled.
+func (v *validator) intValue(node *yaml.Node) (int, bool) {
+	if !isInt(node) && !(v.opts.Coerce && isString(node)) {
+		return 0, false
+	}
+	x, err := strconv.Atoi(node.Value)
+	return x, err == nil
+}
+
+func isString(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!str"
+}
+
+func kindName(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	}
+	return "nothing"
+}
+
+func isBool(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+}
+
+// isValidCPU accepts whole cores (2), fractional cores (0.5) and millicores (250m).
+func isValidCPU(node *yaml.Node) bool {
+	switch node.Tag {
+	case "!!int", "!!float", "!!str":
+		_, suffix, _ := splitQuantity(node.Value)
+		_, ok := parseQuantity("cpu", node.Value)
+		return ok && (suffix == "" || suffix == "m")
+	}
+	return false
+}
+
+// isValidMemory accepts plain byte counts and quantities with a binary
+// (Ki..Ei) or decimal (k/K..E) suffix.
+func isValidMemory(node *yaml.Node) bool {
+	switch node.Tag {
+	case "!!int", "!!str":
+		_, suffix, _ := splitQuantity(node.Value)
+		_, ok := parseQuantity("memory", node.Value)
+		return ok && suffix != "m"
+	}
+	return false
+}
+
+// isZeroQuantity reports whether a quantity accepted by isValidCPU or
+// isValidMemory is zero. The format admits no sign, so zero is the only
+// non-positive value left.
+func isZeroQuantity(node *yaml.Node) bool {
+	q, ok := quantityValue(node.Value)
+	return ok && q.Sign() == 0
+}
+
+// isAbsolutePath reports whether s is an absolute path without '..'
+// segments, which could step out of the directory it names.
+func isAbsolutePath(s string) bool {
+	return strings.HasPrefix(s, "/") && !slices.Contains(strings.Split(s, "/"), "..")
+}
+
+// isDNS1123Label reports whether s is a valid RFC 1123 label: at most 63
+// lowercase alphanumerics or '-', starting and ending with an alphanumeric.
+func isDNS1123Label(s string) bool {
+	return len(s) <= 63 && reDNSLabel.MatchString(s)
+}
+
+// isGenerateName reports whether s is a valid metadata.generateName: a
+// prefix that forms a DNS-1123 label once the server appends its 5 random
+// characters, so it may end with '-'.
+func isGenerateName(s string) bool {
+	return s != "" && len(s) <= 58 && isDNS1123Label(s+"x")
+}
+
+// isDNS1123Subdomain reports whether s is a dot-separated sequence of
+// DNS-1123 labels no longer than 253 characters.
+func isDNS1123Subdomain(s string) bool {
+	if len(s) > 253 {
+		return false
+	}
+	for _, part := range strings.Split(s, ".") {
+		if !isDNS1123Label(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// isLabelKey accepts "name" or "prefix/name" where prefix is a DNS subdomain
+// and name is a label value that is not empty.
+func isLabelKey(s string) bool {
+	name := s
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		if !isDNS1123Subdomain(s[:i]) {
+			return false
+		}
+		name = s[i+1:]
+	}
+	return name != "" && isLabelValue(name)
+}
+
+// isLabelValue accepts an empty string or up to 63 alphanumerics, '-', '_'
+// and '.', starting and ending with an alphanumeric.
+func isLabelValue(s string) bool {
+	return len(s) <= 63 && reLabelValue.MatchString(s)
+}
+
+// isIANASvcName reports whether s is a valid IANA service name: at most 15
+// lowercase alphanumerics or '-', with at least one letter and no leading,
+// trailing or doubled hyphen.
+func isIANASvcName(s string) bool {
+	return len(s) <= 15 &&
+		reDNSLabel.MatchString(s) &&
+		reHasLetter.MatchString(s) &&
+		!strings.Contains(s, "--")
+}
+
+// isExtendedResource reports whether s is a domain-qualified resource name
+// such as nvidia.com/gpu.
+func isExtendedResource(s string) bool {
+	domain, name, ok := strings.Cut(s, "/")
+	return ok && strings.Contains(domain, ".") && isDNS1123Subdomain(domain) && isLabelKey(name)
+}
+
+var (
+	reSnake           = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+	reDNSLabel        = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	reLabelValue      = regexp.MustCompile(`^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$`)
+	reCIdent          = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	reHasLetter       = regexp.MustCompile(`[a-z]`)
+	validPro          = map[string]bool{"TCP": true, "UDP": true, "SCTP": true}
+	validScheme       = map[string]bool{"HTTP": true, "HTTPS": true}
+	validRestart      = map[string]bool{"Always": true, "OnFailure": true, "Never": true}
+	standardResources = map[string]bool{"cpu": true, "memory": true, "ephemeral-storage": true}
+	validTolOp        = map[string]bool{"Exists": true, "Equal": true}
+	validTolEffect    = map[string]bool{"NoSchedule": true, "PreferNoSchedule": true, "NoExecute": true}
+	validPull         = map[string]bool{"Always": true, "Never": true, "IfNotPresent": true}
+	validDNS          = map[string]bool{"ClusterFirst": true, "ClusterFirstWithHostNet": true, "Default": true, "None": true}
+)
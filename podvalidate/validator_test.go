@@ -0,0 +1,153 @@
+package podvalidate
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scalar parses src as a YAML document and returns its root node, so tests
+// see the tags the decoder really assigns.
+func scalar(t *testing.T, src string) *yaml.Node {
+	t.Helper()
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatalf("yaml.Unmarshal(%q): %v", src, err)
+	}
+	return doc.Content[0]
+}
+
+func TestIsValidMemory(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"1073741824", true},
+		{"128974848", true},
+		{"512k", true},
+		{"512K", true},
+		{"512M", true},
+		{"1G", true},
+		{"1T", true},
+		{"1P", true},
+		{"1E", true},
+		{"512Ki", true},
+		{"128Mi", true},
+		{"1Gi", true},
+		{"1.5Gi", true},
+		{"2Ti", true},
+		{"10Pi", true},
+		{"1Ei", true},
+		{`"256Mi"`, true},
+		{`""`, false},
+		{"10GiB", false},
+		{"10gi", false},
+		{"100m", false},
+		{"Mi", false},
+		{"-1Gi", false},
+		{"1 Gi", false},
+		{"true", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		if got := isValidMemory(scalar(t, tt.in)); got != tt.want {
+			t.Errorf("isValidMemory(%s) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMalformedMapping(t *testing.T) {
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "name", Line: 1, Column: 1}
+	n := &yaml.Node{Kind: yaml.MappingNode, Line: 1, Column: 1, Content: []*yaml.Node{key}}
+
+	v := &validator{}
+	if m := v.mapify(n); len(m) != 0 {
+		t.Errorf("mapify kept %d entries of a dangling key", len(m))
+	}
+	if len(v.errs) != 1 || v.errs[0].Message != "malformed mapping" {
+		t.Errorf("got %v, want a single malformed mapping error", v.errs)
+	}
+
+	if m := v.mapify(nil); len(m) != 0 {
+		t.Errorf("mapify(nil) = %v, want empty", m)
+	}
+}
+
+func TestIsInt(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"8080", true},
+		{"0", true},
+		{"-1", true},
+		{`"8080"`, false},
+		{"8080.0", false},
+		{"true", false},
+		{"false", false},
+		{"null", false},
+		{"~", false},
+		{"yes", false},
+		{"[8080]", false},
+		{"{port: 8080}", false},
+		{"99999999999999999999", false},
+	}
+	for _, tt := range tests {
+		if got := isInt(scalar(t, tt.in)); got != tt.want {
+			t.Errorf("isInt(%s) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"web", true},
+		{`"8080"`, true},
+		{`"true"`, true},
+		{`""`, true},
+		{"yes", true},
+		{"8080", false},
+		{"1.5", false},
+		{"true", false},
+		{"null", false},
+		{"~", false},
+		{"[web]", false},
+		{"{name: web}", false},
+	}
+	for _, tt := range tests {
+		if got := isString(scalar(t, tt.in)); got != tt.want {
+			t.Errorf("isString(%s) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsAbsolutePath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"/", true},
+		{"/health", true},
+		{"/var/lib/data", true},
+		{"/var/lib/data/", true},
+		{"//srv", true},
+		{"/srv/..data", true},
+		{"/srv/data..", true},
+		{"", false},
+		{"health", false},
+		{"./health", false},
+		{"var/lib", false},
+		{"..", false},
+		{"/..", false},
+		{"/srv/../etc", false},
+		{"/srv/..", false},
+	}
+	for _, tt := range tests {
+		if got := isAbsolutePath(tt.in); got != tt.want {
+			t.Errorf("isAbsolutePath(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}